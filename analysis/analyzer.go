@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"fmt"
+	"github.com/webklex/juck/report"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File is a single recovered file passed to every analyzer
+type File struct {
+	// Path is relative to the output directory
+	Path string
+	// Source is the unsanitized source path within the source map
+	Source  string
+	Content string
+	// LineOffset is the number of lines preceding the content within the written file
+	LineOffset int
+	// Package is the node module the file belongs to (empty for application sources)
+	Package string
+	Map     *SourceMap
+}
+
+// SourceMap contains the metadata of the source map (target) a file has been recovered from
+type SourceMap struct {
+	// Target is the url (or local path) of the source map
+	Target  string
+	Data    map[string]interface{}
+	Sources []string
+}
+
+// Analyzer inspects recovered files. Analyze gets called for every recovered file and Finish once at the end of
+// the run - it adds the findings to the report
+type Analyzer interface {
+	Name() string
+	Analyze(f *File)
+	Finish(r *report.Report) error
+}
+
+// Factory creates a configured Analyzer
+type Factory func(opts Options) (Analyzer, error)
+
+// Options are the analyzer specific settings taken from the config file and flags
+type Options map[string]string
+
+var factories = map[string]Factory{
+	AnalyzerSecrets:     NewSecrets,
+	AnalyzerEndpoints:   NewEndpoints,
+	AnalyzerEnvironment: NewEnvironment,
+	AnalyzerIndicators:  NewIndicators,
+	AnalyzerComments:    NewComments,
+	AnalyzerBuild:       NewBuild,
+}
+
+//
+// Register
+// @Description: Register an analyzer factory under a given name. Registered analyzers can be enabled by name
+// @param name string
+// @param f Factory
+func Register(name string, f Factory) {
+	factories[name] = f
+}
+
+//
+// Names
+// @Description: Get the names of all registered analyzers
+// @return []string
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//
+// New
+// @Description: Create a registered analyzer by name
+// @param name string
+// @param opts Options
+// @return Analyzer
+// @return error
+func New(name string, opts Options) (Analyzer, error) {
+	f, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("analysis: unknown analyzer %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	if opts == nil {
+		opts = Options{}
+	}
+	return f(opts)
+}
+
+//
+// String
+// @Description: Get an option or a default value
+// @receiver o Options
+// @param key string
+// @param def string
+// @return string
+func (o Options) String(key, def string) string {
+	if v, ok := o[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+//
+// Bool
+// @Description: Get a boolean option - false if it isn't set or invalid
+// @receiver o Options
+// @param key string
+// @return bool
+func (o Options) Bool(key string) bool {
+	b, _ := strconv.ParseBool(o[key])
+	return b
+}
+
+//
+// List
+// @Description: Get a comma separated option as list or a default list
+// @receiver o Options
+// @param key string
+// @param def []string
+// @return []string
+func (o Options) List(key string, def []string) []string {
+	v, ok := o[key]
+	if !ok || v == "" {
+		return def
+	}
+	return strings.Split(v, ",")
+}
+
+//
+// finding
+// @Description: Create a new finding of a given analyzer for a recovered file
+// @param analyzer string
+// @param kind string
+// @param value string
+// @param f *File
+// @param line int
+// @return *report.Finding
+func finding(analyzer, kind, value string, f *File, line int) *report.Finding {
+	return &report.Finding{
+		Analyzer:  analyzer,
+		Kind:      kind,
+		Value:     value,
+		File:      f.Path,
+		Line:      line,
+		SourceMap: f.Map.Target,
+	}
+}
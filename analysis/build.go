@@ -0,0 +1,298 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/report"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	AnalyzerBuild = "build"
+
+	buildBundler   = "bundler"
+	buildFramework = "framework"
+)
+
+// buildRule is a single hint of a bundler or framework. Meta frameworks (e.g. next) have a higher priority than
+// the libraries they are based on (e.g. react)
+type buildRule struct {
+	kind     string
+	name     string
+	version  string
+	priority int
+	weight   float64
+	evidence string
+	match    func(source string) bool
+}
+
+var buildRules = []*buildRule{
+	{buildBundler, "webpack", "", 0, 0.6, "webpack:// source prefix", prefix("webpack://")},
+	{buildBundler, "webpack", "", 0, 0.8, "webpack/bootstrap runtime", contains("webpack/bootstrap")},
+	{buildBundler, "webpack", "5", 0, 0.9, "webpack/runtime modules", contains("webpack/runtime/")},
+	{buildBundler, "webpack", "4", 0, 0.8, "(webpack)/buildin modules", contains("(webpack)/buildin")},
+	{buildBundler, "turbopack", "", 1, 0.9, "turbopack:// source prefix", prefix("turbopack://")},
+	{buildBundler, "turbopack", "", 1, 0.6, "[project] / [turbopack] sources", contains("[turbopack]", "/[project]/")},
+	{buildBundler, "rollup", "", 0, 0.7, "\\0 virtual modules", prefix("\x00")},
+	{buildBundler, "rollup", "", 0, 0.6, "rollup plugin helpers", contains("commonjsHelpers", "rollupPluginBabelHelpers")},
+	{buildBundler, "vite", "", 1, 0.9, "vite helpers", contains("vite/preload-helper", "vite/modulepreload-polyfill", "/@vite/", "\x00vite/")},
+	{buildBundler, "esbuild", "", 0, 0.8, "<define:...> / <stdin> sources", prefix("<define:", "<stdin>", "<runtime>")},
+	{buildBundler, "parcel", "", 0, 0.9, "@parcel runtime modules", contains("@parcel/runtime-", "@parcel/transformer-js", "node_modules/parcel/")},
+	{buildBundler, "metro", "", 0, 0.9, "metro runtime modules", contains("__prelude__", "metro-runtime/", "metro/src/lib/polyfills")},
+	{buildBundler, "browserify", "", 0, 0.9, "browser-pack prelude", contains("browser-pack/_prelude.js")},
+	{buildFramework, "next", "", 1, 0.9, "next.js modules", contains("webpack://_N_E/", "node_modules/next/dist/", "private-next-pages", "private-next-app-dir")},
+	{buildFramework, "nuxt", "", 1, 0.9, "nuxt modules", contains("node_modules/nuxt/dist/", "/.nuxt/", "node_modules/@nuxt/", "node_modules/nuxt3/")},
+	{buildFramework, "sveltekit", "", 1, 0.9, "sveltekit modules", contains("node_modules/@sveltejs/kit/", "/.svelte-kit/")},
+	{buildFramework, "gatsby", "", 1, 0.9, "gatsby modules", contains("node_modules/gatsby/", "/.cache/gatsby-browser-entry", "gatsby-browser.js")},
+	{buildFramework, "remix", "", 1, 0.9, "remix modules", contains("node_modules/@remix-run/")},
+	{buildFramework, "astro", "", 1, 0.9, "astro modules", contains("node_modules/astro/")},
+	{buildFramework, "astro", "", 1, 0.7, "astro components", suffix(".astro")},
+	{buildFramework, "angular", "", 1, 0.9, "@angular/core modules", contains("node_modules/@angular/core/")},
+	{buildFramework, "angular", "", 1, 0.6, "angular cli entry points", contains("src/polyfills.ts", "$_lazy_route_resource", "src/app/app.module.ts")},
+	{buildFramework, "react-native", "", 1, 0.9, "react-native libraries", contains("node_modules/react-native/Libraries/")},
+	{buildFramework, "react", "", 0, 0.8, "react-dom modules", contains("node_modules/react-dom/")},
+	{buildFramework, "react", "", 0, 0.5, "jsx / tsx sources", suffix(".jsx", ".tsx")},
+	{buildFramework, "vue", "", 0, 0.8, "vue runtime modules", contains("node_modules/@vue/runtime-core/", "node_modules/vue/dist/")},
+	{buildFramework, "vue", "", 0, 0.7, "vue single file components", suffix(".vue")},
+	{buildFramework, "svelte", "", 0, 0.8, "svelte modules", contains("node_modules/svelte/")},
+	{buildFramework, "svelte", "", 0, 0.7, "svelte components", suffix(".svelte")},
+	{buildFramework, "preact", "", 0, 0.8, "preact modules", contains("node_modules/preact/")},
+	{buildFramework, "solid", "", 0, 0.8, "solid-js modules", contains("node_modules/solid-js/")},
+	{buildFramework, "ember", "", 0, 0.8, "ember modules", contains("node_modules/ember-source/", "@ember/")},
+}
+
+// frameworkPackages maps frameworks to the package containing their version
+var frameworkPackages = map[string]string{
+	"next":         "next",
+	"nuxt":         "nuxt",
+	"sveltekit":    "@sveltejs/kit",
+	"gatsby":       "gatsby",
+	"remix":        "@remix-run/react",
+	"astro":        "astro",
+	"angular":      "@angular/core",
+	"react-native": "react-native",
+	"react":        "react",
+	"vue":          "vue",
+	"svelte":       "svelte",
+	"preact":       "preact",
+	"solid":        "solid-js",
+	"ember":        "ember-source",
+}
+
+// Build fingerprints the bundler and framework that produced every target
+type Build struct {
+	targets map[string]*targetBuild
+	order   []string
+}
+
+// targetBuild contains the bundler and framework hints of a single target
+type targetBuild struct {
+	target   string
+	versions map[string]string
+	scores   map[string]map[string][]*buildRule
+}
+
+//
+// NewBuild
+// @Description: Create a new Build analyzer
+// @param opts Options
+// @return Analyzer
+// @return error
+func NewBuild(opts Options) (Analyzer, error) {
+	return &Build{
+		targets: map[string]*targetBuild{},
+	}, nil
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *Build
+// @return string
+func (x *Build) Name() string {
+	return AnalyzerBuild
+}
+
+//
+// Analyze
+// @Description: Fingerprint the target of a recovered file. Every target gets only analyzed once
+// @receiver x *Build
+// @param f *File
+func (x *Build) Analyze(f *File) {
+	if _, ok := x.targets[f.Map.Target]; ok {
+		return
+	}
+	t := &targetBuild{
+		target:   f.Map.Target,
+		versions: map[string]string{},
+		scores: map[string]map[string][]*buildRule{
+			buildBundler:   {},
+			buildFramework: {},
+		},
+	}
+	t.analyzeMap(f.Map.Data, f.Map.Sources)
+	x.targets[f.Map.Target] = t
+	x.order = append(x.order, f.Map.Target)
+}
+
+//
+// Finish
+// @Description: Add the bundler and framework of every target to the report. The framework version is taken from
+// the detected package versions
+// @receiver x *Build
+// @param r *report.Report
+// @return error
+func (x *Build) Finish(r *report.Report) error {
+	for _, target := range x.order {
+		b := x.targets[target].summary()
+		for _, p := range r.Packages {
+			if p.Name == FrameworkPackage(b.Framework) {
+				b.FrameworkVersion = p.Version
+			}
+		}
+		log.Info("Build of %s:", target)
+		if b.Bundler == "" && b.Framework == "" {
+			log.Info("Unknown bundler and framework")
+		}
+		if b.Bundler != "" {
+			log.Statistic("Bundler: %s (confidence %.2f)", strings.TrimSpace(b.Bundler+" "+b.BundlerVersion), b.BundlerConfidence)
+			r.AddFindings(&report.Finding{Analyzer: AnalyzerBuild, Kind: buildBundler, Value: strings.TrimSpace(b.Bundler + " " + b.BundlerVersion), SourceMap: target})
+		}
+		if b.Framework != "" {
+			log.Statistic("Framework: %s (confidence %.2f)", strings.TrimSpace(b.Framework+" "+b.FrameworkVersion), b.FrameworkConfidence)
+			r.AddFindings(&report.Finding{Analyzer: AnalyzerBuild, Kind: buildFramework, Value: strings.TrimSpace(b.Framework + " " + b.FrameworkVersion), SourceMap: target})
+		}
+		r.Builds = append(r.Builds, b)
+	}
+	return nil
+}
+
+//
+// analyzeMap
+// @Description: Match the source paths and map fields against all known bundler and framework hints
+// @receiver x *targetBuild
+// @param data map[string]interface{} the decoded source map
+// @param sources []string unsanitized source paths
+func (x *targetBuild) analyzeMap(data map[string]interface{}, sources []string) {
+	if root, ok := data["sourceRoot"].(string); ok && root != "" {
+		sources = append(sources, root)
+	}
+	if _, ok := data["x_facebook_sources"]; ok {
+		sources = append(sources, "__prelude__")
+	}
+	for _, rule := range buildRules {
+		for _, source := range sources {
+			if rule.match(source) {
+				x.add(rule)
+				break
+			}
+		}
+	}
+}
+
+//
+// summary
+// @Description: Get the most likely bundler and framework
+// @receiver x *targetBuild
+// @return *report.Build
+func (x *targetBuild) summary() *report.Build {
+	b := &report.Build{Target: x.target}
+	var evidence []string
+	b.Bundler, b.BundlerConfidence, evidence = x.best(buildBundler)
+	b.Evidence = append(b.Evidence, evidence...)
+	b.BundlerVersion = x.versions[b.Bundler]
+	b.Framework, b.FrameworkConfidence, evidence = x.best(buildFramework)
+	b.Evidence = append(b.Evidence, evidence...)
+	return b
+}
+
+//
+// add
+// @Description: Record a matching rule
+// @receiver x *targetBuild
+// @param rule *buildRule
+func (x *targetBuild) add(rule *buildRule) {
+	x.scores[rule.kind][rule.name] = append(x.scores[rule.kind][rule.name], rule)
+	if rule.version != "" {
+		x.versions[rule.name] = rule.version
+	}
+}
+
+//
+// best
+// @Description: Get the name with the highest priority and confidence of a given kind. The confidence of several
+// hints is combined as 1 - (1 - w1) * (1 - w2) ...
+// @receiver x *targetBuild
+// @param kind string
+// @return name string
+// @return confidence float64
+// @return evidence []string
+func (x *targetBuild) best(kind string) (name string, confidence float64, evidence []string) {
+	names := make([]string, 0, len(x.scores[kind]))
+	for n := range x.scores[kind] {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	priority := -1
+	for _, n := range names {
+		rules := x.scores[kind][n]
+		miss, p := 1.0, 0
+		var ev []string
+		for _, r := range rules {
+			miss *= 1 - r.weight
+			if r.priority > p {
+				p = r.priority
+			}
+			ev = append(ev, n+": "+r.evidence)
+		}
+		c := math.Round((1-miss)*100) / 100
+		if p > priority || (p == priority && c > confidence) {
+			name, confidence, evidence, priority = n, c, ev, p
+		}
+	}
+	return
+}
+
+//
+// FrameworkPackage
+// @Description: Get the name of the package carrying the version of a given framework
+// @param framework string
+// @return string
+func FrameworkPackage(framework string) string {
+	return frameworkPackages[framework]
+}
+
+func prefix(prefixes ...string) func(string) bool {
+	return func(s string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(s, p) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func contains(substrings ...string) func(string) bool {
+	return func(s string) bool {
+		for _, sub := range substrings {
+			if strings.Contains(s, sub) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func suffix(suffixes ...string) func(string) bool {
+	return func(s string) bool {
+		for _, suf := range suffixes {
+			if strings.HasSuffix(s, suf) {
+				return true
+			}
+		}
+		return false
+	}
+}
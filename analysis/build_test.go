@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/report"
+	"testing"
+)
+
+func TestBuildRules(t *testing.T) {
+	// Every rule is identified by its evidence and gets a matching and a non-matching source
+	tests := map[string][2]string{
+		"webpack:// source prefix":        {"webpack:///./src/index.js", "src/webpack://index.js"},
+		"webpack/bootstrap runtime":       {"webpack:///webpack/bootstrap", "webpack:///./src/bootstrap.js"},
+		"webpack/runtime modules":         {"webpack:///webpack/runtime/define property getters", "webpack:///./src/runtime/index.js"},
+		"(webpack)/buildin modules":       {"webpack:///(webpack)/buildin/global.js", "webpack:///./src/buildin.js"},
+		"turbopack:// source prefix":      {"turbopack:///[project]/src/app.tsx", "webpack:///./src/turbopack.js"},
+		"[project] / [turbopack] sources": {"/turbopack/[project]/src/app.tsx", "src/project/app.tsx"},
+		"\\0 virtual modules":             {"\x00commonjsHelpers.js", "src/virtual.js"},
+		"rollup plugin helpers":           {"rollupPluginBabelHelpers.js", "src/helpers.js"},
+		"vite helpers":                    {"vite/preload-helper.js", "src/vite.config.js"},
+		"<define:...> / <stdin> sources":  {"<define:process.env.NODE_ENV>", "src/define.js"},
+		"@parcel runtime modules":         {"node_modules/@parcel/runtime-js/lib/helpers/bundle-url.js", "src/parcel.js"},
+		"metro runtime modules":           {"node_modules/metro-runtime/src/polyfills/require.js", "src/metro.js"},
+		"browser-pack prelude":            {"node_modules/browser-pack/_prelude.js", "src/prelude.js"},
+		"next.js modules":                 {"webpack://_N_E/./pages/index.js", "src/next.js"},
+		"nuxt modules":                    {"webpack:///./.nuxt/client.js", "src/nuxt.js"},
+		"sveltekit modules":               {"../../node_modules/@sveltejs/kit/src/runtime/client/start.js", "src/kit.js"},
+		"gatsby modules":                  {"webpack:///./.cache/gatsby-browser-entry.js", "src/gatsby.js"},
+		"remix modules":                   {"node_modules/@remix-run/react/dist/esm/browser.js", "src/remix.js"},
+		"astro modules":                   {"node_modules/astro/dist/runtime/client/idle.js", "src/astro.js"},
+		"astro components":                {"src/pages/index.astro", "src/pages/astro.js"},
+		"@angular/core modules":           {"webpack:///node_modules/@angular/core/fesm2022/core.mjs", "src/angular.js"},
+		"angular cli entry points":        {"webpack:///src/polyfills.ts", "src/polyfills.js"},
+		"react-native libraries":          {"node_modules/react-native/Libraries/Core/InitializeCore.js", "node_modules/react-native-svg/index.js"},
+		"react-dom modules":               {"webpack:///./node_modules/react-dom/cjs/react-dom.production.min.js", "node_modules/react/index.js"},
+		"jsx / tsx sources":               {"src/App.tsx", "src/App.ts"},
+		"vue runtime modules":             {"node_modules/@vue/runtime-core/dist/runtime-core.esm-bundler.js", "node_modules/vue-router/index.js"},
+		"vue single file components":      {"src/App.vue", "src/vue.js"},
+		"svelte modules":                  {"node_modules/svelte/internal/index.mjs", "src/svelte.js"},
+		"svelte components":               {"src/App.svelte", "src/App.svelte.js"},
+		"preact modules":                  {"node_modules/preact/dist/preact.module.js", "node_modules/preact-router/index.js"},
+		"solid-js modules":                {"node_modules/solid-js/dist/solid.js", "src/solid.js"},
+		"ember modules":                   {"node_modules/ember-source/dist/ember.js", "src/ember.js"},
+	}
+	for _, rule := range buildRules {
+		sources, ok := tests[rule.evidence]
+		if !ok {
+			t.Errorf("%s: rule without test", rule.evidence)
+			continue
+		}
+		if !rule.match(sources[0]) {
+			t.Errorf("%s: expected %s to match", rule.evidence, sources[0])
+		}
+		if rule.match(sources[1]) {
+			t.Errorf("%s: expected %s not to match", rule.evidence, sources[1])
+		}
+	}
+}
+
+func TestBuildSummary(t *testing.T) {
+	tests := []struct {
+		name      string
+		sources   []string
+		data      map[string]interface{}
+		bundler   string
+		version   string
+		framework string
+	}{
+		{"webpack 5 and react", []string{"webpack:///webpack/runtime/jsonp chunk loading", "webpack:///./node_modules/react-dom/index.js", "webpack:///./src/App.jsx"}, nil, "webpack", "5", "react"},
+		{"next over react", []string{"webpack://_N_E/./node_modules/react-dom/index.js", "webpack://_N_E/./pages/_app.tsx"}, nil, "webpack", "", "next"},
+		{"vite over rollup", []string{"\x00vite/preload-helper", "\x00commonjsHelpers.js", "../../src/App.vue"}, nil, "vite", "", "vue"},
+		{"metro by x_facebook_sources", []string{"index.js"}, map[string]interface{}{"x_facebook_sources": []interface{}{}}, "metro", "", ""},
+		{"source root", []string{"src/index.js"}, map[string]interface{}{"sourceRoot": "webpack://"}, "webpack", "", ""},
+		{"unknown", []string{"src/index.js", "src/util.js"}, nil, "", "", ""},
+	}
+	for _, tt := range tests {
+		a, err := NewBuild(Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.data == nil {
+			tt.data = map[string]interface{}{}
+		}
+		a.Analyze(&File{Path: "sources/index.js", Map: &SourceMap{Target: "app.js.map", Data: tt.data, Sources: tt.sources}})
+		r := report.New()
+		r.Packages = append(r.Packages, &report.Package{Name: "react", Version: "18.2.0"})
+		if err := a.Finish(r); err != nil {
+			t.Fatal(err)
+		}
+		b := r.Builds[0]
+		if b.Bundler != tt.bundler || b.BundlerVersion != tt.version || b.Framework != tt.framework {
+			t.Errorf("%s: expected %s %s / %s, got %s %s / %s", tt.name, tt.bundler, tt.version, tt.framework, b.Bundler, b.BundlerVersion, b.Framework)
+		}
+		if b.Framework == "react" && b.FrameworkVersion != "18.2.0" {
+			t.Errorf("%s: expected the react version of the detected packages, got %s", tt.name, b.FrameworkVersion)
+		}
+	}
+}
+
+func TestBuildConfidence(t *testing.T) {
+	x := &targetBuild{versions: map[string]string{}, scores: map[string]map[string][]*buildRule{buildBundler: {}, buildFramework: {}}}
+	x.analyzeMap(map[string]interface{}{}, []string{"webpack:///webpack/bootstrap", "webpack:///./src/index.js"})
+	// 1 - (1 - 0.6) * (1 - 0.8)
+	if name, confidence, evidence := x.best(buildBundler); name != "webpack" || confidence != 0.92 || len(evidence) != 2 {
+		t.Errorf("expected webpack with a confidence of 0.92, got %s %.2f %v", name, confidence, evidence)
+	}
+}
@@ -0,0 +1,188 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/report"
+	"sort"
+	"strings"
+)
+
+const (
+	AnalyzerComments = "comments"
+
+	CommentLine  = "line"
+	CommentBlock = "block"
+	CommentHtml  = "html"
+
+	// maxCommentLength limits the stored comment text
+	maxCommentLength = 500
+)
+
+// DefaultCommentKeywords are the keywords a comment has to contain in order to be reported
+var DefaultCommentKeywords = []string{"TODO", "FIXME", "HACK", "XXX", "password", "bypass", "debug"}
+
+var (
+	scriptExtensions = []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".scss", ".less", ".styl"}
+	markupExtensions = []string{".vue", ".svelte", ".html"}
+)
+
+// Comments collects developer comments containing one of the configured keywords
+type Comments struct {
+	keywords []string
+	comments []*report.Comment
+	findings []*report.Finding
+}
+
+//
+// NewComments
+// @Description: Create a new comment collection
+// @param opts Options "keywords" is a comma separated list of (case-insensitive) keywords a comment has to contain
+// @return Analyzer
+// @return error
+func NewComments(opts Options) (Analyzer, error) {
+	x := &Comments{
+		comments: make([]*report.Comment, 0),
+	}
+	for _, k := range opts.List("keywords", DefaultCommentKeywords) {
+		if k = strings.TrimSpace(k); k != "" {
+			x.keywords = append(x.keywords, k)
+		}
+	}
+	return x, nil
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *Comments
+// @return string
+func (x *Comments) Name() string {
+	return AnalyzerComments
+}
+
+//
+// Analyze
+// @Description: Extract the block and line comments of a JS / TS / CSS / Vue application source
+// @receiver x *Comments
+// @param f *File
+func (x *Comments) Analyze(f *File) {
+	if f.Package != "" {
+		return
+	}
+	file, content := f.Path, f.Content
+	lineComments := hasExtension(file, scriptExtensions) || hasExtension(file, markupExtensions)
+	htmlComments := hasExtension(file, markupExtensions)
+	if !lineComments && !hasExtension(file, []string{".css"}) {
+		return
+	}
+
+	line := 1
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			if quote != '`' {
+				quote = 0
+			}
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			}
+			text := content[i+2 : i+2+end]
+			x.add(f, f.LineOffset+line, CommentBlock, text)
+			line += strings.Count(text, "\n")
+			i += end + 3
+		case lineComments && c == '/' && i+1 < len(content) && content[i+1] == '/':
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			x.add(f, f.LineOffset+line, CommentLine, content[i+2:i+end])
+			i += end - 1
+		case htmlComments && strings.HasPrefix(content[i:], "<!--"):
+			end := strings.Index(content[i+4:], "-->")
+			if end < 0 {
+				end = len(content) - i - 4
+			}
+			text := content[i+4 : i+4+end]
+			x.add(f, f.LineOffset+line, CommentHtml, text)
+			line += strings.Count(text, "\n")
+			i += end + 6
+		}
+	}
+}
+
+//
+// Finish
+// @Description: Add all collected comments to the report
+// @receiver x *Comments
+// @param r *report.Report
+// @return error
+func (x *Comments) Finish(r *report.Report) error {
+	r.Comments = x.Comments()
+	r.AddFindings(x.findings...)
+	return nil
+}
+
+//
+// Comments
+// @Description: Get all collected comments sorted by file and line
+// @receiver x *Comments
+// @return []*report.Comment
+func (x *Comments) Comments() []*report.Comment {
+	sort.SliceStable(x.comments, func(i, j int) bool {
+		if x.comments[i].File != x.comments[j].File {
+			return x.comments[i].File < x.comments[j].File
+		}
+		return x.comments[i].Line < x.comments[j].Line
+	})
+	return x.comments
+}
+
+//
+// add
+// @Description: Add a comment if it contains at least one keyword
+// @receiver x *Comments
+// @param f *File
+// @param line int
+// @param kind string
+// @param text string
+func (x *Comments) add(f *File, line int, kind, text string) {
+	// Strip the decoration of doc blocks (/** ... * ... */)
+	lines := strings.Split(strings.TrimLeft(strings.TrimSpace(text), "*!/"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "*"))
+	}
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+	lower := strings.ToLower(text)
+	var keywords []string
+	for _, k := range x.keywords {
+		if strings.Contains(lower, strings.ToLower(k)) {
+			keywords = append(keywords, k)
+		}
+	}
+	if len(keywords) == 0 {
+		return
+	}
+	if len(text) > maxCommentLength {
+		text = text[:maxCommentLength] + "..."
+	}
+	x.findings = append(x.findings, finding(AnalyzerComments, kind, text, f, line))
+	x.comments = append(x.comments, &report.Comment{
+		File:     f.Path,
+		Line:     line,
+		Kind:     kind,
+		Text:     text,
+		Keywords: keywords,
+	})
+}
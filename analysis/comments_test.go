@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+//
+// commentValues
+// @Description: Run the comments analyzer over a given file and get all comments as "kind line: text"
+// @param t *testing.T
+// @param opts Options
+// @param f *File
+// @return []string
+func commentValues(t *testing.T, opts Options, f *File) []string {
+	a, err := NewComments(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Map = &SourceMap{Target: "app.js.map"}
+	a.Analyze(f)
+	values := make([]string, 0)
+	for _, c := range a.(*Comments).Comments() {
+		values = append(values, fmt.Sprintf("%s %d: %s", c.Kind, c.Line, c.Text))
+	}
+	return values
+}
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected []string
+	}{
+		{"line comment", "src/app.js", "const a = 1;\n// TODO: remove the admin bypass\n", []string{"line 2: TODO: remove the admin bypass"}},
+		{"block comment", "src/app.ts", "/**\n * FIXME: token refresh\n */\nconst a = 1; /* debug only */", []string{"block 1: FIXME: token refresh", "block 4: debug only"}},
+		{"case insensitive", "src/app.js", "// Password reset is broken", []string{"line 1: Password reset is broken"}},
+		{"without keyword", "src/app.js", "// increments the counter\n/* helpers */", []string{}},
+		{"url within a string", "src/app.js", `const u = "https://debug.example.com/todo"; // see TODO`, []string{"line 1: see TODO"}},
+		{"comment within a template literal", "src/app.js", "const s = `\n// TODO not a comment\n`;\n// TODO comment", []string{"line 4: TODO comment"}},
+		{"escaped quote", "src/app.js", `const s = 'it\'s // TODO not a comment'; // XXX hack`, []string{"line 1: XXX hack"}},
+		{"html comment", "src/App.vue", "<template>\n  <!-- TODO: hide the debug panel -->\n</template>", []string{"html 2: TODO: hide the debug panel"}},
+		{"html comment within a script", "src/app.js", "const s = 1; <!-- TODO -->", []string{}},
+		{"css line comment", "src/app.css", "a { background: url(//cdn.example.com/todo.png); }\n/* HACK: safari */", []string{"block 2: HACK: safari"}},
+		{"scss line comment", "src/app.scss", "// TODO: dark mode", []string{"line 1: TODO: dark mode"}},
+		{"unsupported file", "src/data.json", `{"a": "// TODO"}`, []string{}},
+	}
+	for _, tt := range tests {
+		values := commentValues(t, Options{}, &File{Path: tt.path, Content: tt.content})
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, values)
+		}
+	}
+}
+
+func TestCommentsOptions(t *testing.T) {
+	content := "// TODO: cleanup\n// internal: staging only"
+	values := commentValues(t, Options{"keywords": "internal, staging"}, &File{Path: "src/app.js", Content: content, LineOffset: 10})
+	if expected := []string{"line 12: internal: staging only"}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+	if values := commentValues(t, Options{}, &File{Path: "node_modules/a/index.js", Content: content, Package: "a"}); len(values) != 0 {
+		t.Errorf("expected node modules to be skipped, got %v", values)
+	}
+}
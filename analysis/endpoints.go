@@ -0,0 +1,243 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/utils"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	AnalyzerEndpoints = "endpoints"
+
+	EndpointFetch     = "fetch"
+	EndpointAxios     = "axios"
+	EndpointXhr       = "xhr"
+	EndpointGraphQL   = "graphql"
+	EndpointWebSocket = "websocket"
+	EndpointRoute     = "route"
+	EndpointLiteral   = "literal"
+
+	// methodLookahead limits the search for a request method following a fetch call
+	methodLookahead = 200
+)
+
+var (
+	quoted             = "([\"'`])([^\"'`\\s]+)"
+	fetchRegex         = regexp.MustCompile(`\bfetch\(\s*` + quoted + `["'` + "`" + `]`)
+	fetchMethodRegex   = regexp.MustCompile(`^[^)]*?\bmethod\s*:\s*["'](\w+)["']`)
+	axiosRegex         = regexp.MustCompile(`\baxios(?:\.(get|post|put|patch|delete|head|options|request))?\(\s*` + quoted + `["'` + "`" + `]`)
+	axiosBaseUrlRegex  = regexp.MustCompile(`\bbaseURL\s*:\s*` + quoted + `["'` + "`" + `]`)
+	xhrRegex           = regexp.MustCompile(`\.open\(\s*["'](GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)["']\s*,\s*` + quoted + `["'` + "`" + `]`)
+	webSocketRegex     = regexp.MustCompile(`\bnew\s+WebSocket\(\s*` + quoted + `["'` + "`" + `]`)
+	graphQLRegex       = regexp.MustCompile(`(?:\b(?:gql|graphql)\s*\(?\s*` + "`" + `|["'` + "`" + `])\s*(query|mutation|subscription)\s+([A-Za-z_]\w*)\s*[({]`)
+	jsxRouteRegex      = regexp.MustCompile(`<Route\b[^>]*?\bpath=\{?\s*["'` + "`" + `]([^"'` + "`" + `]*)["'` + "`" + `]`)
+	routeObjectRegex   = regexp.MustCompile(`\bpath\s*:\s*["'` + "`" + `]([^"'` + "`" + `\s]*)["'` + "`" + `]`)
+	routerHintRegex    = regexp.MustCompile(`(?i)router|<Route\b|Routes`)
+	absoluteUrlRegex   = regexp.MustCompile(`["'` + "`" + `]((?:https?|wss?)://[^\s"'` + "`" + `<>\\]+)["'` + "`" + `]`)
+	relativeUrlRegex   = regexp.MustCompile(`["'` + "`" + `](/[\w\-.~:@$]*[A-Za-z][\w\-.~:@$]*(?:/[\w\-.~:@${}]*)*(?:\?[^\s"'` + "`" + `]*)?)["'` + "`" + `]`)
+	staticExtensions   = []string{".js", ".mjs", ".css", ".scss", ".map", ".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".woff", ".woff2", ".ttf", ".eot", ".html"}
+	endpointExtensions = []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".vue", ".svelte"}
+)
+
+// Endpoints collects all api endpoints, routes and urls referenced by recovered sources
+type Endpoints struct {
+	endpoints map[string]*report.Endpoint
+	findings  []*report.Finding
+	// vendor enables the endpoint extraction of node module sources
+	vendor bool
+}
+
+//
+// NewEndpoints
+// @Description: Create a new empty endpoint collection
+// @param opts Options "vendor" also extracts the endpoints of node module sources
+// @return Analyzer
+// @return error
+func NewEndpoints(opts Options) (Analyzer, error) {
+	return &Endpoints{
+		endpoints: map[string]*report.Endpoint{},
+		vendor:    opts.Bool("vendor"),
+	}, nil
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *Endpoints
+// @return string
+func (x *Endpoints) Name() string {
+	return AnalyzerEndpoints
+}
+
+//
+// Analyze
+// @Description: Extract all endpoints of a recovered JS / TS source file
+// @receiver x *Endpoints
+// @param f *File
+func (x *Endpoints) Analyze(f *File) {
+	if !hasExtension(f.Path, endpointExtensions) || (f.Package != "" && !x.vendor) {
+		return
+	}
+	content := f.Content
+
+	for _, m := range fetchRegex.FindAllStringSubmatchIndex(content, -1) {
+		method := ""
+		tail := content[m[1]:]
+		if len(tail) > methodLookahead {
+			tail = tail[:methodLookahead]
+		}
+		if mm := fetchMethodRegex.FindStringSubmatch(tail); mm != nil {
+			method = strings.ToUpper(mm[1])
+		}
+		x.add(EndpointFetch, method, content[m[4]:m[5]], f)
+	}
+	for _, m := range axiosRegex.FindAllStringSubmatch(content, -1) {
+		x.add(EndpointAxios, strings.ToUpper(m[1]), m[3], f)
+	}
+	for _, m := range axiosBaseUrlRegex.FindAllStringSubmatch(content, -1) {
+		x.add(EndpointAxios, "", m[2], f)
+	}
+	for _, m := range xhrRegex.FindAllStringSubmatch(content, -1) {
+		x.add(EndpointXhr, m[1], m[3], f)
+	}
+	for _, m := range webSocketRegex.FindAllStringSubmatch(content, -1) {
+		x.add(EndpointWebSocket, "", m[2], f)
+	}
+	for _, m := range graphQLRegex.FindAllStringSubmatch(content, -1) {
+		x.add(EndpointGraphQL, m[1], m[2], f)
+	}
+	if routerHintRegex.MatchString(content) {
+		for _, m := range jsxRouteRegex.FindAllStringSubmatch(content, -1) {
+			x.add(EndpointRoute, "", m[1], f)
+		}
+		for _, m := range routeObjectRegex.FindAllStringSubmatch(content, -1) {
+			x.add(EndpointRoute, "", m[1], f)
+		}
+	}
+	for _, m := range absoluteUrlRegex.FindAllStringSubmatch(content, -1) {
+		if strings.HasPrefix(m[1], "ws") {
+			x.add(EndpointWebSocket, "", m[1], f)
+		} else {
+			x.add(EndpointLiteral, "", m[1], f)
+		}
+	}
+	for _, m := range relativeUrlRegex.FindAllStringSubmatch(content, -1) {
+		if !hasExtension(strings.SplitN(m[1], "?", 2)[0], staticExtensions) {
+			x.add(EndpointLiteral, "", m[1], f)
+		}
+	}
+}
+
+//
+// Finish
+// @Description: Add all collected endpoints to the report
+// @receiver x *Endpoints
+// @param r *report.Report
+// @return error
+func (x *Endpoints) Finish(r *report.Report) error {
+	r.Endpoints = x.Endpoints()
+	known := x.known()
+	for _, f := range x.findings {
+		if f.Kind != EndpointLiteral || !known[f.Value] {
+			r.AddFindings(f)
+		}
+	}
+	return nil
+}
+
+//
+// Endpoints
+// @Description: Get all collected endpoints sorted by host and url
+// @receiver x *Endpoints
+// @return []*report.Endpoint
+func (x *Endpoints) Endpoints() []*report.Endpoint {
+	known := x.known()
+	result := make([]*report.Endpoint, 0, len(x.endpoints))
+	for _, ep := range x.endpoints {
+		// Literals already covered by a more specific kind are dropped
+		if ep.Kind == EndpointLiteral && known[ep.Url] {
+			continue
+		}
+		sort.Strings(ep.Files)
+		result = append(result, ep)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Host != result[j].Host {
+			return result[i].Host < result[j].Host
+		}
+		if result[i].Url != result[j].Url {
+			return result[i].Url < result[j].Url
+		}
+		return result[i].Kind < result[j].Kind
+	})
+	return result
+}
+
+//
+// known
+// @Description: Get all urls found by a more specific kind than literal
+// @receiver x *Endpoints
+// @return map[string]bool
+func (x *Endpoints) known() map[string]bool {
+	known := map[string]bool{}
+	for _, ep := range x.endpoints {
+		if ep.Kind != EndpointLiteral {
+			known[ep.Url] = true
+		}
+	}
+	return known
+}
+
+//
+// add
+// @Description: Add an endpoint or the file referencing an already known endpoint
+// @receiver x *Endpoints
+// @param kind string
+// @param method string
+// @param u string
+// @param f *File
+func (x *Endpoints) add(kind, method, u string, f *File) {
+	if u == "" {
+		return
+	}
+	key := strings.Join([]string{kind, method, u}, " ")
+	ep, ok := x.endpoints[key]
+	if !ok {
+		ep = &report.Endpoint{
+			Kind:   kind,
+			Method: method,
+			Url:    u,
+		}
+		if kind != EndpointGraphQL && kind != EndpointRoute {
+			if parsed, err := url.Parse(u); err == nil {
+				ep.Host = parsed.Host
+			}
+		}
+		x.endpoints[key] = ep
+	}
+	if utils.InStringList(ep.Files, f.Path) {
+		return
+	}
+	ep.Files = append(ep.Files, f.Path)
+	x.findings = append(x.findings, finding(AnalyzerEndpoints, kind, strings.TrimSpace(method+" "+u), f, 0))
+}
+
+//
+// hasExtension
+// @Description: Check if a given filename has one of the given extensions
+// @param filename string
+// @param extensions []string
+// @return bool
+func hasExtension(filename string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
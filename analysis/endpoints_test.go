@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//
+// endpointValues
+// @Description: Run the endpoints analyzer over a given file and get all endpoints as "kind method url"
+// @param t *testing.T
+// @param opts Options
+// @param f *File
+// @return []string
+func endpointValues(t *testing.T, opts Options, f *File) []string {
+	a, err := NewEndpoints(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Map = &SourceMap{Target: "app.js.map"}
+	a.Analyze(f)
+	values := make([]string, 0)
+	for _, ep := range a.(*Endpoints).Endpoints() {
+		values = append(values, strings.Join(strings.Fields(ep.Kind+" "+ep.Method+" "+ep.Url), " "))
+	}
+	return values
+}
+
+func TestEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"fetch", `fetch("/api/users")`, []string{"fetch /api/users"}},
+		{"fetch method", `fetch('/api/users', { method: "post", body: data })`, []string{"fetch POST /api/users"}},
+		{"fetch method of another call", `fetch('/api/users'); save({ method: "post" })`, []string{"fetch /api/users"}},
+		{"fetch variable", `fetch(url)`, []string{}},
+		{"axios", `axios.delete("/api/users/1")`, []string{"axios DELETE /api/users/1"}},
+		{"axios call", "axios(`/api/health`)", []string{"axios /api/health"}},
+		{"axios base url", `axios.create({ baseURL: "https://api.example.com/v2" })`, []string{"axios https://api.example.com/v2"}},
+		{"xhr", `xhr.open("PUT", "/api/profile")`, []string{"xhr PUT /api/profile"}},
+		{"xhr invalid method", `xhr.open("FOO", "/api/profile")`, []string{"literal /api/profile"}},
+		{"websocket", `new WebSocket("wss://ws.example.com/live")`, []string{"websocket wss://ws.example.com/live"}},
+		{"graphql tag", "const Q = gql`query GetUser($id: ID!) { user(id: $id) { name } }`", []string{"graphql query GetUser"}},
+		{"graphql string", `const M = "mutation UpdateUser { updateUser { id } }"`, []string{"graphql mutation UpdateUser"}},
+		{"graphql prose", `const s = "a query about something"`, []string{}},
+		{"jsx route", `<Routes><Route path="/settings/:tab" element={<Settings />} /></Routes>`, []string{"route /settings/:tab"}},
+		{"route object", `const router = createRouter({ routes: [{ path: "/admin", component: Admin }] })`, []string{"route /admin"}},
+		{"path without router", `const config = { path: "/tmp/cache" }`, []string{"literal /tmp/cache"}},
+		{"absolute url", `const docs = "https://docs.example.com/guide"`, []string{"literal https://docs.example.com/guide"}},
+		{"relative url", `const u = "/api/v1/items?page=1"`, []string{"literal /api/v1/items?page=1"}},
+		{"static asset", `const logo = "/static/logo.png"`, []string{}},
+		{"division", `const ratio = a / b / c`, []string{}},
+		{"single slash", `const root = "/"`, []string{}},
+		{"numeric path", `const d = "/2024/01"`, []string{}},
+	}
+	for _, tt := range tests {
+		values := endpointValues(t, Options{}, &File{Path: "sources/src/api.js", Content: tt.content})
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, values)
+		}
+	}
+}
+
+func TestEndpointsSkippedFiles(t *testing.T) {
+	content := `fetch("/api/users")`
+	if values := endpointValues(t, Options{}, &File{Path: "sources/src/app.css", Content: content}); len(values) != 0 {
+		t.Errorf("expected stylesheets to be skipped, got %v", values)
+	}
+	if values := endpointValues(t, Options{}, &File{Path: "sources/node_modules/a/index.js", Content: content, Package: "a"}); len(values) != 0 {
+		t.Errorf("expected node modules to be skipped, got %v", values)
+	}
+	if values := endpointValues(t, Options{"vendor": "true"}, &File{Path: "sources/node_modules/a/index.js", Content: content, Package: "a"}); len(values) != 1 {
+		t.Errorf("expected node modules to be analyzed with the vendor option, got %v", values)
+	}
+}
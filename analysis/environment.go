@@ -0,0 +1,206 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/utils"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	AnalyzerEnvironment = "environment"
+
+	EnvProcess    = "process.env"
+	EnvImportMeta = "import.meta.env"
+	EnvPublic     = "public"
+	EnvConfig     = "config"
+	EnvVariable   = "variable"
+)
+
+var (
+	processEnvRegex     = regexp.MustCompile(`\bprocess\.env(?:\.([A-Za-z_]\w*)|\[\s*["']([^"']+)["']\s*\])`)
+	importMetaEnvRegex  = regexp.MustCompile(`\bimport\.meta\.env\.([A-Za-z_]\w*)`)
+	publicEnvRegex      = regexp.MustCompile(`\b((?:NEXT_PUBLIC|VITE|REACT_APP|VUE_APP|NUXT_PUBLIC|NUXT_ENV|GATSBY|EXPO_PUBLIC|STORYBOOK)_[A-Z0-9_]+)\b`)
+	envFallbackRegex    = regexp.MustCompile(`\b(?:process\.env|import\.meta\.env)\.([A-Za-z_]\w*)\s*(?:\|\||\?\?)\s*(["'` + "`" + `])([^"'` + "`" + `\n]*)["'` + "`" + `]`)
+	envAssignmentRegex  = regexp.MustCompile(`\b([A-Z][A-Z0-9_]*)["']?\s*[:=]\s*(["'` + "`" + `])([^"'` + "`" + `\n]*)["'` + "`" + `]`)
+	configPropertyRegex = regexp.MustCompile(`(?m)^\s*["']?([A-Za-z_]\w*)["']?\s*:\s*(["'` + "`" + `]?)([^,"'` + "`" + `\n{}]*?)["'` + "`" + `]?\s*,?\s*$`)
+	dotenvRegex         = regexp.MustCompile(`(?m)^\s*(?:export\s+)?([A-Za-z_]\w*)\s*=\s*["']?(.*?)["']?\s*$`)
+	configFileRegex     = regexp.MustCompile(`^(?:environment(?:\.[\w-]+)?\.[jt]s|\.env(?:\.[\w-]+)?|(?:next|nuxt|vite|vue|webpack|app|runtime|env)\.config\.[cm]?[jt]s|config(?:\.[\w-]+)?\.(?:[jt]s|json))$`)
+)
+
+// Environment collects the environment variables and config files referenced by the application sources of
+// every target
+type Environment struct {
+	targets map[string]*targetEnvironment
+	order   []string
+}
+
+// targetEnvironment contains the environment variables and config files of a single target
+type targetEnvironment struct {
+	target    string
+	variables map[string]*report.EnvVariable
+	files     []string
+}
+
+//
+// NewEnvironment
+// @Description: Create a new Environment analyzer
+// @param opts Options
+// @return Analyzer
+// @return error
+func NewEnvironment(opts Options) (Analyzer, error) {
+	return &Environment{
+		targets: map[string]*targetEnvironment{},
+	}, nil
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *Environment
+// @return string
+func (x *Environment) Name() string {
+	return AnalyzerEnvironment
+}
+
+//
+// Analyze
+// @Description: Collect the environment variables of a recovered application source. Node module sources are
+// skipped
+// @receiver x *Environment
+// @param f *File
+func (x *Environment) Analyze(f *File) {
+	if f.Package != "" {
+		return
+	}
+	t, ok := x.targets[f.Map.Target]
+	if !ok {
+		t = &targetEnvironment{
+			target:    f.Map.Target,
+			variables: map[string]*report.EnvVariable{},
+			files:     make([]string, 0),
+		}
+		x.targets[f.Map.Target] = t
+		x.order = append(x.order, f.Map.Target)
+	}
+	t.analyze(f.Path, f.Content)
+}
+
+//
+// Finish
+// @Description: Add the environment summary of every target to the report
+// @receiver x *Environment
+// @param r *report.Report
+// @return error
+func (x *Environment) Finish(r *report.Report) error {
+	for _, target := range x.order {
+		env := x.targets[target].summary()
+		r.Environment = append(r.Environment, env)
+		for _, file := range env.Files {
+			r.AddFindings(&report.Finding{Analyzer: AnalyzerEnvironment, Kind: EnvConfig, Value: file, File: file, SourceMap: target})
+		}
+		for _, v := range env.Variables {
+			value := v.Name
+			if len(v.Values) > 0 {
+				value += "=" + strings.Join(v.Values, " | ")
+			}
+			for _, file := range v.Files {
+				r.AddFindings(&report.Finding{Analyzer: AnalyzerEnvironment, Kind: EnvVariable, Value: value, File: file, SourceMap: target})
+			}
+		}
+	}
+	return nil
+}
+
+//
+// analyze
+// @Description: Collect all referenced environment variables and their inlined values of a recovered file.
+// Environment and config files are recorded and their properties added as variables
+// @receiver x *targetEnvironment
+// @param file string
+// @param content string
+func (x *targetEnvironment) analyze(file, content string) {
+	if configFileRegex.MatchString(filepath.Base(file)) {
+		x.files = append(x.files, file)
+		properties := configPropertyRegex
+		if strings.HasPrefix(filepath.Base(file), ".env") {
+			properties = dotenvRegex
+		}
+		for _, m := range properties.FindAllStringSubmatch(content, -1) {
+			if value := m[len(m)-1]; value != "" {
+				x.add(m[1], EnvConfig, value, file)
+			}
+		}
+	}
+
+	for _, m := range processEnvRegex.FindAllStringSubmatch(content, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		x.add(name, EnvProcess, "", file)
+	}
+	for _, m := range importMetaEnvRegex.FindAllStringSubmatch(content, -1) {
+		x.add(m[1], EnvImportMeta, "", file)
+	}
+	for _, m := range publicEnvRegex.FindAllStringSubmatch(content, -1) {
+		x.add(m[1], EnvPublic, "", file)
+	}
+	for _, m := range envFallbackRegex.FindAllStringSubmatch(content, -1) {
+		x.add(m[1], "", m[3], file)
+	}
+	for _, m := range envAssignmentRegex.FindAllStringSubmatch(content, -1) {
+		if _, ok := x.variables[m[1]]; ok {
+			x.add(m[1], "", m[3], file)
+		}
+	}
+}
+
+//
+// summary
+// @Description: Get the collected variables and config files
+// @receiver x *targetEnvironment
+// @return *report.Environment
+func (x *targetEnvironment) summary() *report.Environment {
+	env := &report.Environment{
+		Target:    x.target,
+		Variables: make([]*report.EnvVariable, 0, len(x.variables)),
+		Files:     x.files,
+	}
+	for _, v := range x.variables {
+		sort.Strings(v.Sources)
+		env.Variables = append(env.Variables, v)
+	}
+	sort.Slice(env.Variables, func(i, j int) bool {
+		return env.Variables[i].Name < env.Variables[j].Name
+	})
+	sort.Strings(env.Files)
+	return env
+}
+
+//
+// add
+// @Description: Add a variable reference and an optional value
+// @receiver x *targetEnvironment
+// @param name string
+// @param source string how the variable is referenced (empty if unknown)
+// @param value string
+// @param file string
+func (x *targetEnvironment) add(name, source, value, file string) {
+	v, ok := x.variables[name]
+	if !ok {
+		v = &report.EnvVariable{Name: name}
+		x.variables[name] = v
+	}
+	if source != "" && !utils.InStringList(v.Sources, source) {
+		v.Sources = append(v.Sources, source)
+	}
+	if value = strings.TrimSpace(value); value != "" && !utils.InStringList(v.Values, value) {
+		v.Values = append(v.Values, value)
+	}
+	if !utils.InStringList(v.Files, file) {
+		v.Files = append(v.Files, file)
+	}
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//
+// environmentValues
+// @Description: Run the environment analyzer over the given files and get all variables as "NAME sources=values"
+// @param t *testing.T
+// @param files ...*File
+// @return []string
+// @return []string config files
+func environmentValues(t *testing.T, files ...*File) ([]string, []string) {
+	a, err := NewEnvironment(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		f.Map = &SourceMap{Target: "app.js.map"}
+		a.Analyze(f)
+	}
+	x := a.(*Environment)
+	if len(x.order) == 0 {
+		return []string{}, nil
+	}
+	env := x.targets[x.order[0]].summary()
+	values := make([]string, 0)
+	for _, v := range env.Variables {
+		values = append(values, fmt.Sprintf("%s %s=%s", v.Name, strings.Join(v.Sources, ","), strings.Join(v.Values, "|")))
+	}
+	return values, env.Files
+}
+
+func TestEnvironmentVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"process.env", `const url = process.env.API_URL;`, []string{"API_URL process.env="}},
+		{"process.env index", `const url = process.env["API_URL"];`, []string{"API_URL process.env="}},
+		{"import.meta.env", `const mode = import.meta.env.MODE;`, []string{"MODE import.meta.env="}},
+		{"public prefix", `console.log("NEXT_PUBLIC_SENTRY_DSN is missing")`, []string{"NEXT_PUBLIC_SENTRY_DSN public="}},
+		{"public prefix within process.env", `process.env.VITE_API_KEY`, []string{"VITE_API_KEY process.env,public="}},
+		{"fallback", `const url = process.env.API_URL || "https://api.example.com";`, []string{"API_URL process.env=https://api.example.com"}},
+		{"nullish fallback", "const port = import.meta.env.PORT ?? `8080`;", []string{"PORT import.meta.env=8080"}},
+		{"inlined value", "const env = process.env.STAGE;\nconst a = { STAGE: \"production\" };", []string{"STAGE process.env=production"}},
+		{"unknown constant", `const a = { TIMEOUT: "30" };`, []string{}},
+		{"public prefix without suffix", `const a = "REACT_APP_"`, []string{}},
+		{"process.env object", `const env = process.env;`, []string{}},
+	}
+	for _, tt := range tests {
+		values, _ := environmentValues(t, &File{Path: "sources/src/app.js", Content: tt.content})
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, values)
+		}
+	}
+}
+
+func TestEnvironmentConfigFiles(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected []string
+	}{
+		{"sources/src/environments/environment.prod.ts", "export const environment = {\n  production: true,\n  apiUrl: 'https://api.example.com',\n};", []string{"apiUrl config=https://api.example.com", "production config=true"}},
+		{"sources/.env.production", "export API_URL=\"https://api.example.com\"\nDEBUG=false\nEMPTY=\n", []string{"API_URL config=https://api.example.com", "DEBUG config=false"}},
+		{"sources/vite.config.ts", "export default {\n  base: '/app/',\n}", []string{"base config=/app/"}},
+		{"sources/src/components/environment-banner.ts", "export default {\n  base: '/app/',\n}", []string{}},
+	}
+	for _, tt := range tests {
+		values, files := environmentValues(t, &File{Path: tt.path, Content: tt.content})
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.expected, values)
+		}
+		if isConfig := len(files) == 1 && files[0] == tt.path; isConfig != (len(tt.expected) > 0) {
+			t.Errorf("%s: unexpected config files %v", tt.path, files)
+		}
+	}
+}
+
+func TestEnvironmentSkipsNodeModules(t *testing.T) {
+	values, _ := environmentValues(t, &File{Path: "sources/node_modules/a/index.js", Content: `process.env.NODE_ENV`, Package: "a"})
+	if len(values) != 0 {
+		t.Errorf("expected node modules to be skipped, got %v", values)
+	}
+}
@@ -0,0 +1,249 @@
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/report"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultExternalTimeout limits the total runtime of an external analyzer process
+	DefaultExternalTimeout = 5 * time.Minute
+
+	EventStart  = "start"
+	EventFile   = "file"
+	EventFinish = "finish"
+)
+
+// ExternalEvent is a single JSON line written to the stdin of an external analyzer
+type ExternalEvent struct {
+	Event    string        `json:"event"`
+	Analyzer string        `json:"analyzer,omitempty"`
+	Options  Options       `json:"options,omitempty"`
+	File     *ExternalFile `json:"file,omitempty"`
+}
+
+// ExternalFile is the recovered file of a file event
+type ExternalFile struct {
+	Path       string `json:"path"`
+	Source     string `json:"source"`
+	Content    string `json:"content"`
+	LineOffset int    `json:"line_offset"`
+	Package    string `json:"package"`
+	SourceMap  string `json:"source_map"`
+}
+
+// External runs an executable as analyzer. Recovered files are streamed as JSON lines to its stdin and every JSON
+// line written to its stdout is read as finding
+type External struct {
+	name    string
+	command []string
+	timeout time.Duration
+	opts    Options
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	stdout   io.ReadCloser
+	encoder  *json.Encoder
+	done     chan struct{}
+	findings []*report.Finding
+	// err is the first error that occurred while communicating with the process
+	err error
+}
+
+//
+// NewExternal
+// @Description: Create a factory of an external analyzer
+// @param name string
+// @param command []string executable and its arguments
+// @param timeout time.Duration total runtime limit of the process (0 = DefaultExternalTimeout)
+// @return Factory
+func NewExternal(name string, command []string, timeout time.Duration) Factory {
+	if timeout <= 0 {
+		timeout = DefaultExternalTimeout
+	}
+	return func(opts Options) (Analyzer, error) {
+		if len(command) == 0 || command[0] == "" {
+			return nil, fmt.Errorf("analysis: external analyzer %s has no command", name)
+		}
+		if _, err := exec.LookPath(command[0]); err != nil {
+			return nil, fmt.Errorf("analysis: external analyzer %s: %s", name, err.Error())
+		}
+		return &External{
+			name:    name,
+			command: command,
+			timeout: timeout,
+			opts:    opts,
+		}, nil
+	}
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *External
+// @return string
+func (x *External) Name() string {
+	return x.name
+}
+
+//
+// Analyze
+// @Description: Send a recovered file to the external process. The process gets started along with the first file
+// @receiver x *External
+// @param f *File
+func (x *External) Analyze(f *File) {
+	if x.err != nil {
+		return
+	}
+	if x.cmd == nil {
+		if x.err = x.start(); x.err != nil {
+			log.Error("Failed to start analyzer %s: %s", x.name, x.err.Error())
+			return
+		}
+	}
+	x.send(&ExternalEvent{
+		Event: EventFile,
+		File: &ExternalFile{
+			Path:       f.Path,
+			Source:     f.Source,
+			Content:    f.Content,
+			LineOffset: f.LineOffset,
+			Package:    f.Package,
+			SourceMap:  f.Map.Target,
+		},
+	})
+}
+
+//
+// Finish
+// @Description: Signal the end of the run, wait for the process to exit and add all received findings to the
+// report. Findings received before a failure or timeout are kept
+// @receiver x *External
+// @param r *report.Report
+// @return error
+func (x *External) Finish(r *report.Report) error {
+	if x.cmd == nil {
+		return x.err
+	}
+	x.send(&ExternalEvent{Event: EventFinish})
+	_ = x.stdin.Close()
+	select {
+	case <-x.done:
+	case <-x.ctx.Done():
+		// Orphaned child processes may keep stdout open after the process group got killed
+		_ = x.stdout.Close()
+		<-x.done
+	}
+	err := x.cmd.Wait()
+	timedOut := errors.Is(x.ctx.Err(), context.DeadlineExceeded)
+	x.cancel()
+
+	r.AddFindings(x.findings...)
+
+	switch {
+	case timedOut:
+		return fmt.Errorf("analysis: analyzer %s timed out after %s", x.name, x.timeout)
+	case x.err != nil:
+		return x.err
+	case err != nil:
+		return fmt.Errorf("analysis: analyzer %s: %s", x.name, err.Error())
+	}
+	return nil
+}
+
+//
+// start
+// @Description: Start the external process and the reader collecting its findings. The process runs in its own
+// process group, which is killed entirely once the timeout expires - wrapper scripts would otherwise leave their
+// child processes running
+// @receiver x *External
+// @return error
+func (x *External) start() error {
+	ctx, cancel := context.WithTimeout(context.Background(), x.timeout)
+	cmd := exec.CommandContext(ctx, x.command[0], x.command[1:]...)
+	cmd.Stderr = os.Stderr
+	setProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return err
+	}
+	// The process is only kept once it's running - Finish doesn't wait for processes which never started
+	if err = cmd.Start(); err != nil {
+		cancel()
+		return err
+	}
+	log.Info("Started analyzer %s: %s", x.name, strings.Join(x.command, " "))
+
+	x.ctx, x.cancel = ctx, cancel
+	x.cmd, x.stdin, x.stdout = cmd, stdin, stdout
+	x.encoder = json.NewEncoder(stdin)
+	x.done = make(chan struct{})
+	go x.read(stdout)
+	go func() {
+		<-ctx.Done()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			killProcessGroup(cmd)
+		}
+	}()
+
+	x.send(&ExternalEvent{Event: EventStart, Analyzer: x.name, Options: x.opts})
+	return nil
+}
+
+//
+// send
+// @Description: Write an event to the stdin of the process. Once a write failed, all further events are dropped
+// @receiver x *External
+// @param event *ExternalEvent
+func (x *External) send(event *ExternalEvent) {
+	if x.err != nil {
+		return
+	}
+	if err := x.encoder.Encode(event); err != nil {
+		x.err = fmt.Errorf("analysis: analyzer %s stopped accepting events: %s", x.name, err.Error())
+	}
+}
+
+//
+// read
+// @Description: Read all findings written to stdout. Invalid lines are skipped
+// @receiver x *External
+// @param stdout io.Reader
+func (x *External) read(stdout io.Reader) {
+	defer close(x.done)
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if l := strings.TrimSpace(string(line)); l != "" {
+			f := &report.Finding{}
+			if e := json.Unmarshal([]byte(l), f); e != nil || (f.Kind == "" && f.Value == "") {
+				log.Warning("Analyzer %s: skipping invalid finding: %s", x.name, l)
+			} else {
+				f.Analyzer = x.name
+				x.findings = append(x.findings, f)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
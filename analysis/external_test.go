@@ -0,0 +1,82 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/report"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExternalStartFailure(t *testing.T) {
+	// LookPath finds the plugin, but its interpreter doesn't exist
+	plugin := filepath.Join(t.TempDir(), "plugin")
+	if err := ioutil.WriteFile(plugin, []byte("#!/nonexistent/interpreter\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	x, err := NewExternal("plugin", []string{plugin}, time.Second)(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Analyze(&File{Path: "sources/a.js", Content: "a", Map: &SourceMap{Target: "a.js.map"}})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- x.Finish(report.New())
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error for a plugin which failed to start")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Finish blocked on a plugin which failed to start")
+	}
+}
+
+func TestExternalFindings(t *testing.T) {
+	plugin := filepath.Join(t.TempDir(), "plugin")
+	script := "#!/bin/sh\nwhile read -r line; do\n  case \"$line\" in\n    *'\"event\":\"file\"'*) echo '{\"kind\":\"match\",\"value\":\"x\",\"file\":\"sources/a.js\",\"line\":1}'; echo 'invalid';;\n  esac\ndone\n"
+	if err := ioutil.WriteFile(plugin, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	x, err := NewExternal("plugin", []string{plugin}, 5*time.Second)(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Analyze(&File{Path: "sources/a.js", Content: "a", Map: &SourceMap{Target: "a.js.map"}})
+	r := report.New()
+	if err := x.Finish(r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Findings) != 1 || r.Findings[0].Analyzer != "plugin" || r.Findings[0].Kind != "match" {
+		t.Errorf("unexpected findings: %+v", r.Findings)
+	}
+}
+
+func TestExternalTimeoutKillsChildProcesses(t *testing.T) {
+	// The wrapper script keeps stdout open through its child process
+	plugin := filepath.Join(t.TempDir(), "plugin")
+	if err := ioutil.WriteFile(plugin, []byte("#!/bin/sh\ncat >/dev/null\nsleep 30\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	x, err := NewExternal("plugin", []string{plugin}, time.Second)(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Analyze(&File{Path: "sources/a.js", Content: "a", Map: &SourceMap{Target: "a.js.map"}})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- x.Finish(report.New())
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("expected a timeout error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Finish blocked on the child process of a plugin")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package analysis
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+//
+// setProcessGroup
+// @Description: Start the process in its own process group, so its child processes can be killed along with it
+// @param cmd *exec.Cmd
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+//
+// killProcessGroup
+// @Description: Kill the process and all child processes of its process group
+// @param cmd *exec.Cmd
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
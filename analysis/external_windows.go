@@ -0,0 +1,23 @@
+//go:build windows
+
+package analysis
+
+import (
+	"os/exec"
+)
+
+//
+// setProcessGroup
+// @Description: Process groups aren't supported on windows - the process is started as is
+// @param cmd *exec.Cmd
+func setProcessGroup(cmd *exec.Cmd) {}
+
+//
+// killProcessGroup
+// @Description: Kill the process
+// @param cmd *exec.Cmd
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+}
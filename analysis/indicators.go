@@ -0,0 +1,201 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/utils"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	AnalyzerIndicators = "indicators"
+
+	IndicatorHostname = "hostname"
+	IndicatorEmail    = "email"
+	IndicatorIP       = "ip"
+	IndicatorBucket   = "bucket"
+	IndicatorUsername = "username"
+)
+
+type bucketPattern struct {
+	scheme string
+	regex  *regexp.Regexp
+}
+
+var (
+	// internalHostRegex only matches hostnames within a hostname context: string literals, urls (//) and emails (@).
+	// Dotted identifiers (this.host.local, config.api.internal) are property access
+	internalHostRegex = regexp.MustCompile(`(?i)(["'` + "`" + `]|//|@)((?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+(?:corp|internal|intranet|intra|local|localdomain|lan|priv|private|home\.arpa))(?::\d+)?(?:[\s"'` + "`" + `/:?#),]|$)`)
+	// propertyAccessRegex matches string literals which are property paths of common objects (this.api.internal)
+	propertyAccessRegex = regexp.MustCompile(`^(?:this|self|window|globalThis|process|config|options|props|state)\.`)
+	emailRegex          = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)
+	privateIPRegex      = regexp.MustCompile(`(?:^|[^\w.@-])((?:10|172|192|169)\.\d{1,3}\.\d{1,3}\.\d{1,3})(?:$|[^\w.-])`)
+	usernameRegexes     = []*regexp.Regexp{
+		regexp.MustCompile(`(?:^|[^\w])/(?:home|Users)/([A-Za-z0-9._-]+)/`),
+		regexp.MustCompile(`(?i)\b[a-z]:(?:\\\\|\\|/)Users(?:\\\\|\\|/)([A-Za-z0-9._ -]+?)(?:\\\\|\\|/)`),
+	}
+	bucketPatterns = []*bucketPattern{
+		{"s3", regexp.MustCompile(`\b([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])\.s3(?:[.-][a-z0-9-]+)?\.amazonaws\.com`)},
+		{"s3", regexp.MustCompile(`(?:^|[^\w.-])s3(?:[.-][a-z0-9-]+)?\.amazonaws\.com/([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])`)},
+		{"s3", regexp.MustCompile(`\bs3://([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])`)},
+		{"gs", regexp.MustCompile(`\bstorage\.googleapis\.com/([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])`)},
+		{"gs", regexp.MustCompile(`\b([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])\.storage\.googleapis\.com`)},
+		{"gs", regexp.MustCompile(`\bgs://([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])`)},
+		{"gs", regexp.MustCompile(`\b([a-z0-9][a-z0-9-]{1,61}[a-z0-9]\.appspot\.com)\b`)},
+		{"azure", regexp.MustCompile(`\b([a-z0-9]{3,24})\.blob\.core\.windows\.net`)},
+		{"spaces", regexp.MustCompile(`\b([a-z0-9][a-z0-9-]{1,61}[a-z0-9])\.[a-z0-9-]+\.digitaloceanspaces\.com`)},
+	}
+	// ignoredEmailDomains contains documentation domains and file extensions of retina assets (logo@2x.png)
+	ignoredEmailDomains = []string{"example.com", "example.org", "example.net", "domain.com", "email.com", "png", "jpg", "jpeg", "gif", "svg", "webp", "js", "css"}
+	ignoredUsernames    = []string{"runner", "shared", "public", "default", "default user", "all users", "user", "username", "me"}
+)
+
+// Indicators collects internal hostnames, emails, private ip addresses, cloud buckets and usernames
+type Indicators struct {
+	indicators map[string]*report.Indicator
+	findings   []*report.Finding
+	// targets contains all targets with analyzed metadata
+	targets map[string]bool
+}
+
+//
+// NewIndicators
+// @Description: Create a new empty indicator collection
+// @param opts Options
+// @return Analyzer
+// @return error
+func NewIndicators(opts Options) (Analyzer, error) {
+	return &Indicators{
+		indicators: map[string]*report.Indicator{},
+		targets:    map[string]bool{},
+	}, nil
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *Indicators
+// @return string
+func (x *Indicators) Name() string {
+	return AnalyzerIndicators
+}
+
+//
+// Analyze
+// @Description: Collect all indicators of a recovered file. The source map metadata (source paths, source root
+// and file name) gets analyzed along with the first file of every target
+// @receiver x *Indicators
+// @param f *File
+func (x *Indicators) Analyze(f *File) {
+	if !x.targets[f.Map.Target] {
+		x.targets[f.Map.Target] = true
+		metadata := append([]string{}, f.Map.Sources...)
+		for _, key := range []string{"sourceRoot", "file"} {
+			if v, ok := f.Map.Data[key].(string); ok && v != "" {
+				metadata = append(metadata, v)
+			}
+		}
+		x.analyze(&File{Path: f.Map.Target, Map: f.Map}, strings.Join(metadata, "\n"))
+	}
+	x.analyze(f, f.Content)
+}
+
+//
+// Finish
+// @Description: Add all collected indicators to the report
+// @receiver x *Indicators
+// @param r *report.Report
+// @return error
+func (x *Indicators) Finish(r *report.Report) error {
+	r.Indicators = x.Indicators()
+	r.AddFindings(x.findings...)
+	return nil
+}
+
+//
+// analyze
+// @Description: Collect all indicators of a given content
+// @receiver x *Indicators
+// @param f *File the content is attributed to
+// @param content string
+func (x *Indicators) analyze(f *File, content string) {
+	for _, m := range internalHostRegex.FindAllStringSubmatch(content, -1) {
+		if propertyAccessRegex.MatchString(m[2]) {
+			continue
+		}
+		// Single labels within string literals (e.g. "app.local") are most likely keys unless used within an url or
+		// email
+		if strings.Count(m[2], ".") > 1 || m[1] == "//" || m[1] == "@" {
+			x.add(IndicatorHostname, strings.ToLower(m[2]), f)
+		}
+	}
+	for _, m := range emailRegex.FindAllString(content, -1) {
+		domain := strings.ToLower(m[strings.LastIndex(m, "@")+1:])
+		ignored := false
+		for _, d := range ignoredEmailDomains {
+			ignored = ignored || domain == d || strings.HasSuffix(domain, "."+d)
+		}
+		if !ignored {
+			x.add(IndicatorEmail, strings.ToLower(m), f)
+		}
+	}
+	for _, m := range privateIPRegex.FindAllStringSubmatch(content, -1) {
+		if ip := net.ParseIP(m[1]); ip != nil && (ip.IsPrivate() || ip.IsLinkLocalUnicast()) {
+			x.add(IndicatorIP, m[1], f)
+		}
+	}
+	for _, b := range bucketPatterns {
+		for _, m := range b.regex.FindAllStringSubmatch(content, -1) {
+			x.add(IndicatorBucket, b.scheme+"://"+m[1], f)
+		}
+	}
+	for _, r := range usernameRegexes {
+		for _, m := range r.FindAllStringSubmatch(content, -1) {
+			if !utils.InStringList(ignoredUsernames, strings.ToLower(m[1])) {
+				x.add(IndicatorUsername, m[1], f)
+			}
+		}
+	}
+}
+
+//
+// Indicators
+// @Description: Get all collected indicators sorted by kind and value
+// @receiver x *Indicators
+// @return []*report.Indicator
+func (x *Indicators) Indicators() []*report.Indicator {
+	result := make([]*report.Indicator, 0, len(x.indicators))
+	for _, i := range x.indicators {
+		sort.Strings(i.Files)
+		result = append(result, i)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
+
+//
+// add
+// @Description: Add an indicator or the file referencing an already known indicator
+// @receiver x *Indicators
+// @param kind string
+// @param value string
+// @param f *File
+func (x *Indicators) add(kind, value string, f *File) {
+	key := kind + " " + value
+	i, ok := x.indicators[key]
+	if !ok {
+		i = &report.Indicator{Kind: kind, Value: value}
+		x.indicators[key] = i
+	}
+	if !utils.InStringList(i.Files, f.Path) {
+		i.Files = append(i.Files, f.Path)
+		x.findings = append(x.findings, finding(AnalyzerIndicators, kind, value, f, 0))
+	}
+}
@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"testing"
+)
+
+//
+// indicatorValues
+// @Description: Run the indicators analyzer over a given content and get the values of a given kind
+// @param t *testing.T
+// @param kind string
+// @param content string
+// @return []string
+func indicatorValues(t *testing.T, kind, content string) []string {
+	a, err := NewIndicators(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Analyze(&File{Path: "sources/src/app.js", Content: content, Map: &SourceMap{Target: "app.js.map"}})
+	values := make([]string, 0)
+	for _, i := range a.(*Indicators).Indicators() {
+		if i.Kind == kind {
+			values = append(values, i.Value)
+		}
+	}
+	return values
+}
+
+func TestIndicatorHostnames(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{`fetch("https://api.corp.internal/v1/users")`, []string{"api.corp.internal"}},
+		{`const base = "//jenkins.local:8080/job"`, []string{"jenkins.local"}},
+		{`const host = 'db.prod.lan';`, []string{"db.prod.lan"}},
+		{"const url = `http://Grafana.Intranet/d/`", []string{"grafana.intranet"}},
+		{`mail("ops@build01.corp")`, []string{"build01.corp"}},
+		{`const router = "gw.home.arpa"`, []string{"gw.home.arpa"}},
+		// property access
+		{`const host = this.host.local;`, nil},
+		{`if (config.api.internal) {}`, nil},
+		{`return options.server.private`, nil},
+		{`x = a.b.corp + "/"`, nil},
+		{`const key = "this.host.local"`, nil},
+		{"`${config.api.internal}`", nil},
+		// single labels are only hostnames within urls and emails
+		{`t("settings.local")`, nil},
+		{`fetch("http://app.local/")`, []string{"app.local"}},
+		// public hostnames
+		{`fetch("https://api.example.com/")`, nil},
+	}
+	for _, tt := range tests {
+		values := indicatorValues(t, IndicatorHostname, tt.content)
+		if len(values) != len(tt.expected) || (len(values) > 0 && values[0] != tt.expected[0]) {
+			t.Errorf("%s: expected %v, got %v", tt.content, tt.expected, values)
+		}
+	}
+}
+
+func TestIndicators(t *testing.T) {
+	tests := []struct {
+		kind     string
+		content  string
+		expected []string
+	}{
+		// emails
+		{IndicatorEmail, `const support = "Jane.Doe@Acme-Corp.com";`, []string{"jane.doe@acme-corp.com"}},
+		{IndicatorEmail, `// contact: user@example.com`, nil},
+		{IndicatorEmail, `const logo = "logo@2x.png";`, nil},
+		{IndicatorEmail, `import x from "@babel/runtime"`, nil},
+		// ip addresses
+		{IndicatorIP, `const api = "http://10.0.12.5:8080/";`, []string{"10.0.12.5"}},
+		{IndicatorIP, `const db = '192.168.1.20'`, []string{"192.168.1.20"}},
+		{IndicatorIP, `const meta = "169.254.169.254"`, []string{"169.254.169.254"}},
+		{IndicatorIP, `const dns = "172.16.0.1"`, []string{"172.16.0.1"}},
+		{IndicatorIP, `const dns = "172.32.0.1"`, nil},
+		{IndicatorIP, `const version = "10.2.3.4.5"`, nil},
+		{IndicatorIP, `const dns = "8.8.8.8"`, nil},
+		// buckets
+		{IndicatorBucket, `const cdn = "https://acme-assets.s3.eu-west-1.amazonaws.com/logo.png"`, []string{"s3://acme-assets"}},
+		{IndicatorBucket, `const cdn = "https://s3.amazonaws.com/acme-backups/db.sql"`, []string{"s3://acme-backups"}},
+		{IndicatorBucket, `const uri = "s3://acme-logs/2024"`, []string{"s3://acme-logs"}},
+		{IndicatorBucket, `const u = "https://storage.googleapis.com/acme-media/a.mp4"`, []string{"gs://acme-media"}},
+		{IndicatorBucket, `const u = "gs://acme-exports"`, []string{"gs://acme-exports"}},
+		{IndicatorBucket, `const u = "https://acme-prod.appspot.com"`, []string{"gs://acme-prod.appspot.com"}},
+		{IndicatorBucket, `const u = "https://acmestore.blob.core.windows.net/files"`, []string{"azure://acmestore"}},
+		{IndicatorBucket, `const u = "https://acme-files.fra1.digitaloceanspaces.com/a.zip"`, []string{"spaces://acme-files"}},
+		{IndicatorBucket, `const u = "https://aws.amazon.com/s3/"`, nil},
+		// usernames
+		{IndicatorUsername, `//# sourceURL=/home/jdoe/projects/app/src/index.js`, []string{"jdoe"}},
+		{IndicatorUsername, `"/Users/alice/work/app/node_modules/a.js"`, []string{"alice"}},
+		{IndicatorUsername, `"C:\\Users\\bob\\app\\src\\index.js"`, []string{"bob"}},
+		{IndicatorUsername, `"/home/runner/work/app/app/src/index.js"`, nil},
+		{IndicatorUsername, `"C:/Users/Public/Documents/a.js"`, nil},
+		{IndicatorUsername, `const route = "/api/home/users/"`, nil},
+	}
+	for _, tt := range tests {
+		values := indicatorValues(t, tt.kind, tt.content)
+		if len(values) != len(tt.expected) || (len(values) > 0 && values[0] != tt.expected[0]) {
+			t.Errorf("%s %s: expected %v, got %v", tt.kind, tt.content, tt.expected, values)
+		}
+	}
+}
+
+func TestIndicatorsSourceMapMetadata(t *testing.T) {
+	a, err := NewIndicators(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &SourceMap{
+		Target:  "app.js.map",
+		Data:    map[string]interface{}{"sourceRoot": "/Users/carol/app/"},
+		Sources: []string{"webpack:///./src/index.js"},
+	}
+	a.Analyze(&File{Path: "sources/src/index.js", Content: "", Map: m})
+	a.Analyze(&File{Path: "sources/src/app.js", Content: "", Map: m})
+	indicators := a.(*Indicators).Indicators()
+	if len(indicators) != 1 || indicators[0].Value != "carol" || indicators[0].Files[0] != "app.js.map" {
+		t.Errorf("expected the source root username to be attributed to the source map, got %+v", indicators)
+	}
+}
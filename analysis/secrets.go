@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/secrets"
+)
+
+const (
+	AnalyzerSecrets = "secrets"
+
+	severityHigh = "high"
+)
+
+// Secrets scans all recovered files for secrets
+type Secrets struct {
+	scanner  *secrets.Scanner
+	secrets  []*report.Secret
+	findings []*report.Finding
+}
+
+//
+// NewSecrets
+// @Description: Create a new secret scanner
+// @param opts Options "rules" is a yaml file containing custom rules and allowlists
+// @return Analyzer
+// @return error
+func NewSecrets(opts Options) (Analyzer, error) {
+	x := &Secrets{
+		scanner: secrets.NewScanner(),
+	}
+	if rules := opts.String("rules", ""); rules != "" {
+		c, err := secrets.LoadConfig(rules)
+		if err != nil {
+			return nil, err
+		}
+		if err := x.scanner.Configure(c); err != nil {
+			return nil, err
+		}
+		log.Info("Loaded %d custom secret rules", len(c.Rules))
+	}
+	return x, nil
+}
+
+//
+// Name
+// @Description: Get the analyzer name
+// @receiver x *Secrets
+// @return string
+func (x *Secrets) Name() string {
+	return AnalyzerSecrets
+}
+
+//
+// Analyze
+// @Description: Scan a recovered file for secrets
+// @receiver x *Secrets
+// @param f *File
+func (x *Secrets) Analyze(f *File) {
+	for _, s := range x.scanner.Scan(f.Path, f.Content, f.LineOffset) {
+		log.Warning("Secret discovered: %s (%s:%d)", s.Description, s.File, s.Line)
+		x.secrets = append(x.secrets, &report.Secret{
+			Rule:        s.Rule,
+			Description: s.Description,
+			File:        s.File,
+			Line:        s.Line,
+			SourceMap:   f.Map.Target,
+			Match:       s.Match,
+		})
+		fi := finding(AnalyzerSecrets, s.Rule, s.Match, f, s.Line)
+		fi.Message, fi.Severity = s.Description, severityHigh
+		x.findings = append(x.findings, fi)
+	}
+}
+
+//
+// Finish
+// @Description: Add all found secrets to the report
+// @receiver x *Secrets
+// @param r *report.Report
+// @return error
+func (x *Secrets) Finish(r *report.Report) error {
+	r.Secrets = x.secrets
+	r.AddFindings(x.findings...)
+	return nil
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"github.com/webklex/juck/analysis"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/utils"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the optional config file
+type Config struct {
+	Analyzers []*AnalyzerConfig `yaml:"analyzers"`
+}
+
+// AnalyzerConfig enables an analyzer and contains its options. Analyzers with a command are external executables
+type AnalyzerConfig struct {
+	Name    string            `yaml:"name"`
+	Options map[string]string `yaml:"options"`
+	Command []string          `yaml:"command"`
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+//
+// loadConfig
+// @Description: Load the config file (if given)
+// @receiver a *Application
+// @return *Config
+// @return error
+func (a *Application) loadConfig() (*Config, error) {
+	c := &Config{}
+	if a.Config == "" {
+		return c, nil
+	}
+	content, err := ioutil.ReadFile(a.Config)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//
+// newAnalyzers
+// @Description: Create all enabled analyzers. The analyzers are taken from --analyzers, the config file or all
+// registered analyzers (in this order). The legacy flags are applied as analyzer options
+// @receiver a *Application
+// @return []analysis.Analyzer
+// @return error
+func (a *Application) newAnalyzers() ([]analysis.Analyzer, error) {
+	c, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	options := map[string]analysis.Options{}
+	var names []string
+	for _, ac := range c.Analyzers {
+		names = append(names, ac.Name)
+		options[ac.Name] = ac.Options
+		if len(ac.Command) > 0 {
+			analysis.Register(ac.Name, analysis.NewExternal(ac.Name, ac.Command, ac.Timeout))
+		}
+	}
+	if a.Analyzers != "" {
+		names = strings.Split(a.Analyzers, ",")
+	} else if len(names) == 0 {
+		names = analysis.Names()
+	}
+
+	// Flags override the options of the config file if set
+	option := func(name, key, value string) {
+		if options[name] == nil {
+			options[name] = analysis.Options{}
+		}
+		options[name][key] = value
+	}
+	if a.SecretRules != "" {
+		option(analysis.AnalyzerSecrets, "rules", a.SecretRules)
+	}
+	if a.VendorEndpoints {
+		option(analysis.AnalyzerEndpoints, "vendor", strconv.FormatBool(a.VendorEndpoints))
+	}
+	if a.CommentKeywords != strings.Join(analysis.DefaultCommentKeywords, ",") {
+		option(analysis.AnalyzerComments, "keywords", a.CommentKeywords)
+	}
+
+	var analyzers []analysis.Analyzer
+	for _, name := range utils.UniqueStringList(names) {
+		name = strings.TrimSpace(name)
+		if name == "" || (a.DisableSecrets && name == analysis.AnalyzerSecrets) {
+			continue
+		}
+		x, err := analysis.New(name, options[name])
+		if err != nil {
+			return nil, err
+		}
+		analyzers = append(analyzers, x)
+	}
+	log.Info("Enabled analyzers: %d", len(analyzers))
+	return analyzers, nil
+}
+
+//
+// finishAnalyzers
+// @Description: Finish all analyzers and write their results
+// @receiver a *Application
+// @param analyzers []analysis.Analyzer
+// @param r *report.Report
+// @return error
+func (a *Application) finishAnalyzers(analyzers []analysis.Analyzer, r *report.Report) error {
+	for _, x := range analyzers {
+		count := len(r.Findings)
+		if err := x.Finish(r); err != nil {
+			log.Error("Analyzer %s failed: %s", x.Name(), err.Error())
+		}
+		log.Statistic("Findings of %s: %d", x.Name(), len(r.Findings)-count)
+	}
+	if err := writeFindings(path.Join(a.OutputDir, "findings.txt"), r.Findings); err != nil {
+		return err
+	}
+	if err := writeIndicators(path.Join(a.OutputDir, "indicators.txt"), r.Indicators); err != nil {
+		return err
+	}
+	if err := report.WriteJson(path.Join(a.OutputDir, "environment.json"), r.Environment); err != nil {
+		return err
+	}
+	return writeEndpoints(path.Join(a.OutputDir, "endpoints.txt"), r.Endpoints)
+}
@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"github.com/webklex/juck/analysis"
 	"github.com/webklex/juck/log"
 	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
 	"github.com/webklex/juck/utils"
 	"io"
 	"io/ioutil"
@@ -19,6 +21,9 @@ import (
 	"time"
 )
 
+// unresolvedVersion is the version of all modules which couldn't be resolved by the registry
+const unresolvedVersion = "<unresolved>"
+
 type Application struct {
 	OutputDir             string
 	SourceFile            string
@@ -31,7 +36,32 @@ type Application struct {
 	LocalOnly             bool
 	DangerouslyWritePaths bool
 	Combined              bool
+	OsvDatabase           string
+	Registry              string
+	PublicRegistry        string
+	ScopeRegistries       string
+	RegistryToken         string
+	Npmrc                 string
+	TarballCache          string
+	NpmCache              string
+	CacheTTL              time.Duration
+	Offline               bool
+	Fingerprint           bool
+	FingerprintCandidates int
+	Workers               int
+	DependencyKinds       string
+	GraphDepth            int
+	GraphFilter           string
+	AbandonedAfter        time.Duration
+	SecretRules           string
+	DisableSecrets        bool
+	VendorEndpoints       bool
+	CommentKeywords       string
+	Analyzers             string
+	Config                string
 	sources               []string
+	// sourceUrls maps downloaded source maps to their origin
+	sourceUrls map[string]string
 }
 
 //
@@ -40,6 +70,10 @@ type Application struct {
 // @return *Application
 func NewApplication() *Application {
 	dir, _ := os.Getwd()
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
 	return &Application{
 		OutputDir:             path.Join(dir, "output"),
 		SourceFile:            "",
@@ -50,7 +84,20 @@ func NewApplication() *Application {
 		DangerouslyWritePaths: false,
 		Combined:              false,
 		LocalOnly:             false,
+		Registry:              "",
+		PublicRegistry:        npm.DefaultRegistry,
+		TarballCache:          path.Join(cacheDir, "juck", "tarballs"),
+		NpmCache:              path.Join(cacheDir, "juck", "npm"),
+		CacheTTL:              npm.DefaultCacheTTL,
+		Offline:               false,
+		Fingerprint:           false,
+		FingerprintCandidates: 20,
+		Workers:               npm.DefaultWorkers,
+		DependencyKinds:       strings.Join(npm.DefaultKinds, ","),
+		CommentKeywords:       strings.Join(analysis.DefaultCommentKeywords, ","),
+		AbandonedAfter:        2 * 365 * 24 * time.Hour,
 		sources:               make([]string, 0),
+		sourceUrls:            map[string]string{},
 	}
 }
 
@@ -64,19 +111,51 @@ func (a *Application) Run() error {
 		return err
 	}
 
+	n, err := a.newRegistry()
+	if err != nil {
+		return err
+	}
+
+	analyzers, err := a.newAnalyzers()
+	if err != nil {
+		return err
+	}
+
 	log.Statistic("Verified sources: %d", len(a.sources))
 	var coreModules []string
+	evidence := map[string][]*report.Evidence{}
+	hashes := map[string][]string{}
+	files := map[string][]string{}
+	registered := map[string]bool{}
+	var assets []*report.Asset
+	var imports []*report.StyleImport
 	for _, source := range a.sources {
 		e := NewExtractor(a.OutputDir)
 		e.Combine(a.Combined)
+		e.UseRegistry(n)
+		e.UseAnalyzers(analyzers)
+		if u, ok := a.sourceUrls[source]; ok {
+			e.SourceMap(u)
+		}
 		if nm, err := e.Extract(source); err != nil {
 			log.Error(err)
 		} else {
 			coreModules = append(coreModules, nm...)
 		}
+		for name, ev := range e.Evidence() {
+			evidence[name] = append(evidence[name], ev...)
+		}
+		for name, h := range e.Hashes() {
+			hashes[name] = append(hashes[name], h...)
+		}
+		for name, f := range e.Files() {
+			files[name] = append(files[name], f...)
+			registered[name] = registered[name] || e.Registered(name)
+		}
+		assets = append(assets, e.Assets()...)
+		imports = append(imports, e.Imports()...)
 	}
 
-	n := npm.NewNpmRegistry()
 	coreModules = utils.UniqueStringList(coreModules)
 	sort.Strings(coreModules)
 
@@ -93,17 +172,95 @@ func (a *Application) Run() error {
 		}
 	}
 
-	nodeModules := coreModules
-	nmc := len(nodeModules)
+	r := report.New()
+	r.Assets = assets
+	log.Statistic("Recovered assets: %d", len(assets))
+	if err := report.WriteJson(path.Join(a.OutputDir, "assets.json"), assets); err != nil {
+		return err
+	}
+	r.Stylesheets = imports
+	if err := report.WriteJson(path.Join(a.OutputDir, "stylesheets.json"), imports); err != nil {
+		return err
+	}
+	if err := writeStyleGraph(path.Join(a.OutputDir, "stylesheets.dot"), imports); err != nil {
+		return err
+	}
 	for _, name := range coreModules {
-		log.Info("Analyzing %s", name)
-		if dependencies, _ := n.Dependencies(name, nodeModules...); dependencies != nil {
-			nodeModules = utils.UniqueStringList(dependencies)
-			if delta := len(nodeModules) - nmc; delta > 0 {
-				log.Info("\t%d new dependencies discovered", delta)
-				nmc = nmc + delta
+		p := r.Package(name)
+		p.Evidence = evidence[name]
+		p.Version, p.Confidence = resolveVersion(p.Evidence)
+		if a.Fingerprint && p.Confidence < fingerprintThreshold {
+			log.Info("Fingerprinting %s", name)
+			if ev, err := fingerprint(n, name, utils.UniqueStringList(hashes[name]), a.FingerprintCandidates); err != nil {
+				log.Error(err)
+			} else if ev != nil {
+				p.Evidence = append(p.Evidence, ev)
+				p.Version, p.Confidence = resolveVersion(p.Evidence)
 			}
 		}
+		if p.Version != "" {
+			log.Success("Detected version: %s@%s (confidence %.2f)", name, p.Version, p.Confidence)
+		}
+	}
+
+	for _, p := range r.Packages {
+		p.Classification = classify(n, p.Name, registered[p.Name])
+		if p.Classification != ClassificationUnregistered && p.Classification != ClassificationUnclaimedScope {
+			continue
+		}
+		log.Warning("Dependency confusion candidate: %s (%s)", p.Name, p.Classification)
+		r.Confusion = append(r.Confusion, &report.Candidate{
+			Name:           p.Name,
+			Scope:          packageScope(p.Name),
+			Classification: p.Classification,
+			Sources:        utils.UniqueStringList(files[p.Name]),
+		})
+	}
+	log.Statistic("Dependency confusion candidates: %d", len(r.Confusion))
+	if err := writeConfusion(path.Join(a.OutputDir, "confusion.txt"), r.Confusion); err != nil {
+		return err
+	}
+
+	for _, p := range r.Packages {
+		if p.Classification != ClassificationPublic {
+			continue
+		}
+		for _, risk := range assessRisks(n, p, a.AbandonedAfter) {
+			log.Warning("Risky package: %s (%s: %s)", p.Name, risk.Kind, risk.Message)
+			r.Risks = append(r.Risks, risk)
+		}
+	}
+	log.Statistic("Package risks: %d", len(r.Risks))
+
+	for name := range files {
+		files[name] = utils.UniqueStringList(files[name])
+		sort.Strings(files[name])
+	}
+	enrichPackages(n, r.Packages, files)
+	if err := writePackagesCsv(path.Join(a.OutputDir, "node_modules.csv"), r.Packages); err != nil {
+		return err
+	}
+	if err := report.WriteJson(path.Join(a.OutputDir, "node_modules.json"), r.Packages); err != nil {
+		return err
+	}
+
+	if err := a.finishAnalyzers(analyzers, r); err != nil {
+		return err
+	}
+
+	roots := map[string]string{}
+	for _, p := range r.Packages {
+		roots[p.Name] = p.Version
+	}
+	log.Info("Resolving dependencies of %d node modules", len(roots))
+	graph := n.Resolve(roots)
+
+	// Modules unknown to the registry keep the name@version format
+	nodeModules := graph.Keys()
+	for _, name := range coreModules {
+		if _, ok := graph.Roots[name]; !ok {
+			nodeModules = append(nodeModules, name+"@"+unresolvedVersion)
+		}
 	}
 
 	sort.Strings(nodeModules)
@@ -119,8 +276,16 @@ func (a *Application) Run() error {
 			return err
 		}
 	}
+	if err := report.WriteJson(path.Join(a.OutputDir, "dependencies.lock.json"), graph.Lockfile()); err != nil {
+		return err
+	}
+	if err := a.writeGraph(graph); err != nil {
+		return err
+	}
 
-	return nil
+	r.Dependencies = nodeModules
+
+	return r.Save(a.OutputDir)
 }
 
 //
@@ -232,6 +397,7 @@ func (a *Application) downloadList(list []string) {
 				log.Error(err)
 			} else {
 				a.sources = append(a.sources, filename)
+				a.sourceUrls[filename] = u.String()
 			}
 		}
 	}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/report"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	dataUriRegex = regexp.MustCompile(`data:((?:image|font|audio|video|application)/[\w.+-]+)((?:;[\w-]+=[\w.-]+)*)(;base64)?,`)
+	// inlineAssetRegex matches webpack asset/inline modules exporting a single data uri
+	inlineAssetRegex = regexp.MustCompile(`^\s*(?:module\.exports\s*=|export\s+default)\s*["']data:[^"']+["'];?\s*$`)
+	assetExtensions  = map[string]string{
+		"image/png":                     ".png",
+		"image/jpeg":                    ".jpg",
+		"image/jpg":                     ".jpg",
+		"image/gif":                     ".gif",
+		"image/webp":                    ".webp",
+		"image/avif":                    ".avif",
+		"image/bmp":                     ".bmp",
+		"image/svg+xml":                 ".svg",
+		"image/x-icon":                  ".ico",
+		"image/vnd.microsoft.icon":      ".ico",
+		"font/woff":                     ".woff",
+		"font/woff2":                    ".woff2",
+		"font/ttf":                      ".ttf",
+		"font/otf":                      ".otf",
+		"application/font-woff":         ".woff",
+		"application/font-woff2":        ".woff2",
+		"application/x-font-woff":       ".woff",
+		"application/x-font-ttf":        ".ttf",
+		"application/x-font-truetype":   ".ttf",
+		"application/x-font-opentype":   ".otf",
+		"application/font-sfnt":         ".ttf",
+		"application/vnd.ms-fontobject": ".eot",
+		"audio/mpeg":                    ".mp3",
+		"audio/wav":                     ".wav",
+		"audio/ogg":                     ".ogg",
+		"video/mp4":                     ".mp4",
+		"video/webm":                    ".webm",
+	}
+)
+
+//
+// extractAssets
+// @Description: Decode all base64 / url encoded data uris of a recovered file into the assets folder. Assets are
+// named after the importing module (sources/src/App.js -> assets/src/App-1.png). The asset of a webpack asset/inline
+// module takes the module path itself (sources/src/logo.png.js -> assets/src/logo.png)
+// @receiver e *Extractor
+// @param sourcePath string
+// @param content string
+// @return []*report.Asset
+func (e *Extractor) extractAssets(sourcePath, content string) []*report.Asset {
+	matches := dataUriRegex.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	module, err := filepath.Rel(path.Join(e.dir, "sources"), sourcePath)
+	if err != nil || strings.HasPrefix(module, "..") {
+		return nil
+	}
+	module = filepath.ToSlash(module)
+	inline := len(matches) == 1 && inlineAssetRegex.MatchString(content)
+
+	var assets []*report.Asset
+	hashes := map[string]bool{}
+	for _, m := range matches {
+		mimeType := strings.ToLower(content[m[2]:m[3]])
+		ext := assetExtension(mimeType)
+		if ext == "" {
+			continue
+		}
+		data, err := decodeDataUri(dataUriPayload(content, m[0], m[1]), m[6] >= 0)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		hash := hashContent(string(data))
+		if hashes[hash] {
+			continue
+		}
+		hashes[hash] = true
+
+		name := strings.TrimSuffix(module, path.Ext(module))
+		if inline {
+			// The module path usually carries the asset extension already (logo.png.js -> logo.png)
+			if path.Ext(name) != ext {
+				name += ext
+			}
+		} else {
+			name = fmt.Sprintf("%s-%d%s", name, len(assets)+1, ext)
+		}
+		target := path.Join(e.dir, "assets", name)
+		if err := makeDirIfNotExist(filepath.Dir(target)); err != nil {
+			log.Error("Failed to create directory \"%s\": %s", target, err.Error())
+			continue
+		}
+		if err := ioutil.WriteFile(target, data, 0600); err != nil {
+			log.Error("Failed to write asset \"%s\": %s", target, err.Error())
+			continue
+		}
+		log.Success("Recovered asset: %s", target)
+
+		file, _ := filepath.Rel(e.dir, target)
+		importer, _ := filepath.Rel(e.dir, sourcePath)
+		assets = append(assets, &report.Asset{
+			File:      file,
+			Module:    importer,
+			MimeType:  mimeType,
+			Size:      len(data),
+			Sha256:    hash,
+			SourceMap: e.sourceMap,
+		})
+	}
+	return assets
+}
+
+//
+// dataUriPayload
+// @Description: Get the payload of a data uri. It ends at the quote or parenthesis enclosing the uri
+// @param content string
+// @param start int index of the data uri
+// @param offset int index of the payload
+// @return string
+func dataUriPayload(content string, start, offset int) string {
+	end := "\"'`) \t\r\n\\"
+	if start > 0 {
+		switch c := content[start-1]; c {
+		case '"', '\'', '`':
+			end = string(c) + "\r\n\\"
+		case '(':
+			end = ")\r\n\\\"'"
+		}
+	}
+	payload := content[offset:]
+	if i := strings.IndexAny(payload, end); i >= 0 {
+		payload = payload[:i]
+	}
+	return payload
+}
+
+//
+// decodeDataUri
+// @Description: Decode the payload of a data uri
+// @param payload string
+// @param base64Encoded bool
+// @return []byte
+// @return error
+func decodeDataUri(payload string, base64Encoded bool) ([]byte, error) {
+	if !base64Encoded {
+		s, err := url.PathUnescape(payload)
+		return []byte(s), err
+	}
+	payload = strings.TrimRight(payload, "=")
+	if p, err := url.PathUnescape(payload); err == nil {
+		payload = p
+	}
+	return base64.RawStdEncoding.DecodeString(payload)
+}
+
+//
+// assetExtension
+// @Description: Get the file extension of an embedded asset mime type
+// @param mimeType string
+// @return string empty if the mime type isn't an image, font, audio or video
+func assetExtension(mimeType string) string {
+	if ext, ok := assetExtensions[mimeType]; ok {
+		return ext
+	}
+	if strings.HasPrefix(mimeType, "application/") {
+		return ""
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/osv"
+	"github.com/webklex/juck/report"
+	"strings"
+)
+
+//
+// Audit
+// @Description: Match all previously discovered packages against a local OSV database dump
+// @receiver a *Application
+// @return error
+func (a *Application) Audit() error {
+	if a.OsvDatabase == "" {
+		return errors.New("no osv database specified. please use --db and provide a directory or zip file")
+	}
+
+	r, err := report.Load(a.OutputDir)
+	if err != nil {
+		return err
+	}
+
+	db, err := osv.Load(a.OsvDatabase)
+	if err != nil {
+		return err
+	}
+	log.Statistic("Loaded advisories: %d", db.Count())
+
+	vulnerable := 0
+	for _, p := range r.Packages {
+		p.Advisories = make([]*report.Advisory, 0)
+		for _, e := range db.Match(p.Name, p.Version) {
+			p.Advisories = append(p.Advisories, &report.Advisory{
+				ID:         e.ID,
+				Aliases:    e.Aliases,
+				Summary:    e.Summary,
+				Severity:   e.SeverityLevel(),
+				Fixed:      e.Fixed(p.Name),
+				References: e.Urls(),
+				Confirmed:  p.Version != "",
+			})
+		}
+		if len(p.Advisories) == 0 {
+			continue
+		}
+		vulnerable++
+
+		version := p.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		log.Warning("%s (%s): %d advisories", p.Name, version, len(p.Advisories))
+		for _, adv := range p.Advisories {
+			fixed := "no fix available"
+			if len(adv.Fixed) > 0 {
+				fixed = "fixed in " + strings.Join(adv.Fixed, ", ")
+			}
+			log.Warning("\t%s [%s] %s (%s)", adv.ID, adv.Severity, adv.Summary, fixed)
+		}
+	}
+	log.Statistic("Vulnerable packages: %d", vulnerable)
+
+	return r.Save(a.OutputDir)
+}
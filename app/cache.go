@@ -0,0 +1,85 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"time"
+)
+
+const (
+	CacheList  = "list"
+	CachePrune = "prune"
+	CacheClear = "clear"
+)
+
+//
+// Cache
+// @Description: Inspect, prune or clear the persistent registry metadata cache
+// @receiver a *Application
+// @param action string
+// @return error
+func (a *Application) Cache(action string) error {
+	if a.NpmCache == "" {
+		return errors.New("no cache directory specified. please use --npm-cache")
+	}
+	c := npm.NewDiskCache(a.NpmCache, a.CacheTTL)
+
+	switch action {
+	case CacheList, "":
+		entries, err := c.Entries()
+		if err != nil {
+			return err
+		}
+		var size int64
+		stale := 0
+		for _, e := range entries {
+			state := "fresh"
+			if !c.Fresh(e) {
+				state = "stale"
+				stale++
+			}
+			if e.NotFound {
+				state += ", not found"
+			}
+			if e.Accept == npm.AcceptAbbreviated {
+				state += ", abbreviated"
+			}
+			size += e.Size()
+			log.Info("%s (%s, %s old, %s)", e.Url, state, time.Since(e.Fetched).Round(time.Second), formatSize(e.Size()))
+		}
+		log.Statistic("Cache directory: %s", c.Dir())
+		log.Statistic("Cached documents: %d (%d stale)", len(entries), stale)
+		log.Statistic("Cache size: %s", formatSize(size))
+	case CachePrune:
+		removed, err := c.Prune()
+		if err != nil {
+			return err
+		}
+		log.Statistic("Pruned documents: %d", removed)
+	case CacheClear:
+		if err := c.Clear(); err != nil {
+			return err
+		}
+		log.Success("Cleared cache: %s", c.Dir())
+	default:
+		return fmt.Errorf("unknown cache action \"%s\". please use %s, %s or %s", action, CacheList, CachePrune, CacheClear)
+	}
+	return nil
+}
+
+//
+// formatSize
+// @Description: Format a byte size human readable
+// @param size int64
+// @return string
+func formatSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	value := float64(size)
+	i := 0
+	for ; value >= 1024 && i < len(units)-1; i++ {
+		value /= 1024
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
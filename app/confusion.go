@@ -0,0 +1,97 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
+	"os"
+	"strings"
+)
+
+const (
+	ClassificationPublic         = "public"
+	ClassificationUnclaimedScope = "unclaimed-scope"
+	ClassificationUnregistered   = "unregistered"
+	ClassificationInvalid        = "invalid"
+	ClassificationUnknown        = "unknown"
+)
+
+//
+// classify
+// @Description: Classify a discovered package name by its status on the public registry. Unregistered names and
+// names within an unclaimed scope can be registered by anyone and are dependency confusion candidates - even if they
+// exist on a mirror or a private scope registry
+// @param n *npm.Npm
+// @param name string
+// @param registered bool true if the name has already been verified by the registry
+// @return string
+func classify(n *npm.Npm, name string, registered bool) string {
+	// Names verified by a mirror or a scope registry may still be unclaimed publicly
+	if c := n.Config(); registered && c.RegistryFor(name) == c.PublicRegistry {
+		return ClassificationPublic
+	}
+	var invalid *npm.InvalidNameError
+	if _, err := n.GetPublic(name); err == nil {
+		return ClassificationPublic
+	} else if errors.As(err, &invalid) {
+		// Invalid names can't be published
+		return ClassificationInvalid
+	} else if errors.Is(err, npm.ErrNotFound) == false {
+		log.Warning("Failed to classify %s: %s", name, strings.TrimSpace(err.Error()))
+		return ClassificationUnknown
+	}
+
+	if scope := packageScope(name); scope != "" {
+		exists, err := n.ScopeExists(scope)
+		if err != nil {
+			log.Warning("Failed to verify scope %s: %s", scope, strings.TrimSpace(err.Error()))
+			return ClassificationUnknown
+		}
+		if !exists {
+			return ClassificationUnclaimedScope
+		}
+	}
+	return ClassificationUnregistered
+}
+
+//
+// packageScope
+// @Description: Get the scope of a package name (e.g. @babel for @babel/core)
+// @param name string
+// @return string
+func packageScope(name string) string {
+	if strings.HasPrefix(name, "@") {
+		if i := strings.Index(name, "/"); i > 0 {
+			return name[:i]
+		}
+	}
+	return ""
+}
+
+//
+// writeConfusion
+// @Description: Write all dependency confusion candidates and their referencing source files
+// @param filename string
+// @param candidates []*report.Candidate
+// @return error
+func writeConfusion(filename string, candidates []*report.Candidate) error {
+	fh, err := os.OpenFile(filename, os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	for _, c := range candidates {
+		if _, err := fh.WriteString(fmt.Sprintf("%s [%s]\n", c.Name, c.Classification)); err != nil {
+			return err
+		}
+		for _, source := range c.Sources {
+			if _, err := fh.WriteString(fmt.Sprintf("\t%s\n", source)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
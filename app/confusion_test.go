@@ -0,0 +1,52 @@
+package app
+
+import (
+	"github.com/webklex/juck/npm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClassifyAgainstPublicRegistry(t *testing.T) {
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/lodash":
+			_, _ = w.Write([]byte(`{"name":"lodash"}`))
+		case "/-/org/babel/package":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer public.Close()
+	// The mirror knows every package, but answers the scope endpoints with 404
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == "/-/org/babel/package" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"mirrored"}`))
+	}))
+	defer mirror.Close()
+
+	n := npm.NewNpmRegistry()
+	n.SetRegistry(mirror.URL)
+	n.Config().SetPublicRegistry(public.URL)
+
+	tests := []struct {
+		name       string
+		registered bool
+		expected   string
+	}{
+		{"lodash", true, ClassificationPublic},
+		{"internal-lib", true, ClassificationUnregistered},
+		{"@acme/internal-lib", true, ClassificationUnclaimedScope},
+		{"@babel/internal-lib", true, ClassificationUnregistered},
+		{"Invalid Name", false, ClassificationInvalid},
+	}
+	for _, tt := range tests {
+		if c := classify(n, tt.name, tt.registered); c != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, c)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/webklex/juck/analysis"
 	"github.com/webklex/juck/log"
 	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
 	"io/ioutil"
 	"os"
 	"path"
@@ -14,12 +16,27 @@ import (
 )
 
 type Extractor struct {
-	dir      string
-	data     map[string]interface{}
-	sources  []string
+	dir     string
+	data    map[string]interface{}
+	sources []string
+	// raw contains the unsanitized sources
+	raw      []string
 	contents []string
 	combined bool
 	npm      *npm.Npm
+	evidence map[string][]*report.Evidence
+	hashes   map[string][]string
+	files    map[string][]string
+	// registered contains all module names verified by the registry
+	registered map[string]bool
+	// sourceMap is the url (or local path) of the extracted source map
+	sourceMap string
+	analyzers []analysis.Analyzer
+	assets    []*report.Asset
+	// stylesheet is true for css source maps
+	stylesheet bool
+	styles     []*styleFile
+	imports    []*report.StyleImport
 }
 
 //
@@ -29,12 +46,17 @@ type Extractor struct {
 // @return *Extractor
 func NewExtractor(dir string) *Extractor {
 	return &Extractor{
-		dir:      dir,
-		data:     map[string]interface{}{},
-		sources:  make([]string, 0),
-		contents: make([]string, 0),
-		combined: false,
-		npm:      npm.NewNpmRegistry(),
+		dir:        dir,
+		data:       map[string]interface{}{},
+		sources:    make([]string, 0),
+		raw:        make([]string, 0),
+		contents:   make([]string, 0),
+		combined:   false,
+		npm:        npm.NewNpmRegistry(),
+		evidence:   map[string][]*report.Evidence{},
+		hashes:     map[string][]string{},
+		files:      map[string][]string{},
+		registered: map[string]bool{},
 	}
 }
 
@@ -46,6 +68,9 @@ func NewExtractor(dir string) *Extractor {
 // @return error
 func (e *Extractor) Extract(filename string) (nodeModules []string, err error) {
 	log.Info("Extracting: %s", filename)
+	if e.sourceMap == "" {
+		e.sourceMap = filename
+	}
 
 	if err = e.load(filename); err != nil {
 		return
@@ -57,6 +82,13 @@ func (e *Extractor) Extract(filename string) (nodeModules []string, err error) {
 	if err = e.parseContents(); err != nil {
 		return
 	}
+	e.stylesheet = e.isStylesheetMap(filename)
+
+	sm := &analysis.SourceMap{
+		Target:  e.sourceMap,
+		Data:    e.data,
+		Sources: e.raw,
+	}
 
 	if err = makeDirIfNotExist(path.Join(e.dir, "combined")); err != nil {
 		return
@@ -66,6 +98,9 @@ func (e *Extractor) Extract(filename string) (nodeModules []string, err error) {
 	}
 
 	targetFile := "combined.js"
+	if e.stylesheet {
+		targetFile = "combined.css"
+	}
 	var tfh *os.File
 	if e.combined {
 		if tf, ok := e.data["file"]; ok && tf != "" {
@@ -94,89 +129,159 @@ func (e *Extractor) Extract(filename string) (nodeModules []string, err error) {
 	}
 
 	for i, content := range e.contents {
-		sourcePath := path.Join(e.dir, "sources", fmt.Sprintf("undefined-%d.js", i))
+		sourcePath := path.Join(e.dir, "sources", fmt.Sprintf("undefined-%d", i))
+		raw := ""
 		if i < sc {
 			sourcePath = e.sources[i]
+			raw = e.raw[i]
 		}
 		if content == "" {
 			log.Warning("Skipping %s -  no content", sourcePath)
 			continue
 		}
-		if ext := filepath.Ext(sourcePath); ext == "" {
+		if e.stylesheet {
+			sourcePath = stylesheetPath(sourcePath, raw)
+		} else if ext := filepath.Ext(sourcePath); ext == "" {
 			sourcePath = sourcePath + ".js"
 		}
 
-		if name := e.getModuleName(sourcePath); name != "" {
+		file, _ := filepath.Rel(e.dir, sourcePath)
+		name := ""
+		if ref := recognizePackage(raw); ref != nil {
+			var registered bool
+			name, registered = e.getModuleName(ref)
 			nodeModules = append(nodeModules, name)
+			e.files[name] = append(e.files[name], file)
+			if registered {
+				e.registered[name] = true
+			}
+			if ev := pathEvidence(ref, file); ev != nil {
+				e.evidence[name] = append(e.evidence[name], ev)
+			}
+			e.evidence[name] = append(e.evidence[name], detectVersions(name, sourcePath, file, content)...)
+			e.hashes[name] = append(e.hashes[name], hashContent(content))
 		}
 
 		if err := makeDirIfNotExist(filepath.Dir(sourcePath)); err != nil {
 			log.Error("Failed to create directory \"%s\": %s", sourcePath, err.Error())
-		} else {
-			e.saveSource(sourcePath, content, tfh)
+			continue
+		}
+		lineOffset := e.saveSource(sourcePath, content, tfh)
+		e.assets = append(e.assets, e.extractAssets(sourcePath, content)...)
+		if hasStyleExtension(sourcePath) {
+			e.addStylesheet(sourcePath, content)
+		}
+		for _, x := range e.analyzers {
+			x.Analyze(&analysis.File{
+				Path:       file,
+				Source:     raw,
+				Content:    content,
+				LineOffset: lineOffset,
+				Package:    name,
+				Map:        sm,
+			})
 		}
 	}
+	nodeModules = append(nodeModules, e.resolveStyleImports()...)
 	return
 }
 
-func (e *Extractor) getModuleName(sourcePath string) string {
-	if i := strings.Index(sourcePath, "node_modules"); i >= 0 {
-		if len(sourcePath) > i+13 {
-			parts := strings.SplitN(sourcePath[i+13:], "/", 3)
-			if len(parts) == 3 {
-				author := parts[0]
-				repository := parts[1]
-				reference := parts[2]
-
-				if r, _ := e.npm.Get(strings.Join([]string{author, repository}, "/")); r == nil {
-					if r, _ = e.npm.Get(author); r != nil {
-						log.Success("Node module discovered: %s (%s/%s)", r.Name(), repository, reference)
-						return r.Name()
-					}
-				} else if r != nil {
-					log.Success("Node module discovered: %s (%s)", r.Name(), reference)
-					return r.Name()
-				}
+//
+// Evidence
+// @Description: Get all collected version evidence grouped by module name
+// @receiver e *Extractor
+// @return map[string][]*report.Evidence
+func (e *Extractor) Evidence() map[string][]*report.Evidence {
+	return e.evidence
+}
 
-				if author[0:1] == "@" {
-					log.Success("Node module discovered: %s/%s (%s)", author, repository, reference)
-					return strings.Join([]string{author, repository}, "/")
-				}
-				log.Success("Node module discovered: %s (%s/%s)", author, repository, reference)
-				return author
-			} else if parts = strings.SplitN(sourcePath[i+13:], "/", 2); len(parts) == 2 {
-				author := parts[0]
-				repository := parts[1]
-
-				if r, _ := e.npm.Get(strings.Join([]string{author, repository}, "/")); r == nil {
-					if r, _ = e.npm.Get(author); r != nil {
-						log.Success("Node module discovered: %s (%s)", r.Name(), repository)
-						return r.Name()
-					}
-				} else if r != nil {
-					log.Success("Node module discovered: %s", r.Name())
-					return r.Name()
-				}
+//
+// Hashes
+// @Description: Get the sha256 hashes of all recovered files grouped by module name
+// @receiver e *Extractor
+// @return map[string][]string
+func (e *Extractor) Hashes() map[string][]string {
+	return e.hashes
+}
 
-				if author[0:1] == "@" {
-					log.Success("Node module discovered: %s/%s", author, repository)
-					return strings.Join([]string{author, repository}, "/")
-				}
-				log.Success("Node module discovered: %s (%s)", author, repository)
-				return author
-			} else {
-				r, _ := e.npm.Get(sourcePath[i+13:])
-				if r != nil {
-					log.Success("Node module discovered: %s", r.Name())
-					return r.Name()
-				}
+//
+// Files
+// @Description: Get all recovered files (relative to the output directory) grouped by module name
+// @receiver e *Extractor
+// @return map[string][]string
+func (e *Extractor) Files() map[string][]string {
+	return e.files
+}
 
-				log.Success("Node module discovered: %s", sourcePath[i+13:])
-				return sourcePath[i+13:]
-			}
-		}
+//
+// Assets
+// @Description: Get all embedded assets recovered from the sources
+// @receiver e *Extractor
+// @return []*report.Asset
+func (e *Extractor) Assets() []*report.Asset {
+	return e.assets
+}
+
+//
+// Imports
+// @Description: Get the stylesheet import graph of the recovered stylesheets
+// @receiver e *Extractor
+// @return []*report.StyleImport
+func (e *Extractor) Imports() []*report.StyleImport {
+	return e.imports
+}
+
+//
+// Registered
+// @Description: Check if a discovered module name has been verified by the registry
+// @receiver e *Extractor
+// @param name string
+// @return bool
+func (e *Extractor) Registered(name string) bool {
+	return e.registered[name]
+}
+
+//
+// UseRegistry
+// @Description: Use a given npm registry client for module lookups
+// @receiver e *Extractor
+// @param n *npm.Npm
+func (e *Extractor) UseRegistry(n *npm.Npm) {
+	e.npm = n
+}
+
+//
+// UseAnalyzers
+// @Description: Pass every recovered file to the given analyzers
+// @receiver e *Extractor
+// @param analyzers []analysis.Analyzer
+func (e *Extractor) UseAnalyzers(analyzers []analysis.Analyzer) {
+	e.analyzers = analyzers
+}
+
+//
+// SourceMap
+// @Description: Set the url the source map has been downloaded from. It's used to attribute findings
+// @receiver e *Extractor
+// @param u string
+func (e *Extractor) SourceMap(u string) {
+	e.sourceMap = u
+}
+
+//
+// getModuleName
+// @Description: Verify a recognized package by the registry
+// @receiver e *Extractor
+// @param ref *PackageRef
+// @return name string
+// @return registered bool false if the name couldn't be verified by the registry
+func (e *Extractor) getModuleName(ref *PackageRef) (name string, registered bool) {
+	if r, _ := e.npm.Get(ref.Name); r != nil {
+		log.Success("Node module discovered: %s (%s)", r.Name(), ref.Layout)
+		return r.Name(), true
 	}
-	return ""
+	log.Success("Node module discovered: %s (%s)", ref.Name, ref.Layout)
+	return ref.Name, false
 }
 
 //
@@ -186,7 +291,8 @@ func (e *Extractor) getModuleName(sourcePath string) string {
 // @param sourcePath string
 // @param content string
 // @param tfh *os.File
-func (e *Extractor) saveSource(sourcePath, content string, tfh *os.File) {
+// @return lineOffset int number of lines preceding the content within the file
+func (e *Extractor) saveSource(sourcePath, content string, tfh *os.File) (lineOffset int) {
 	f, err := os.OpenFile(sourcePath, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0600)
 
 	if err != nil {
@@ -197,6 +303,12 @@ func (e *Extractor) saveSource(sourcePath, content string, tfh *os.File) {
 		if err != nil {
 			log.Error("Failed to read file \"%s\": %s", sourcePath, err.Error())
 		} else {
+			offset := strings.Index(string(data), content)
+			if offset < 0 {
+				offset = len(data)
+			}
+			lineOffset = strings.Count(string(data[:offset]), "\n")
+
 			if strings.Contains(string(data), content) == false {
 				if e.combined {
 					if _, err := tfh.WriteString(fmt.Sprintf("\n/**\nRestored: %s\n**/\n\n%s\n\n", sourcePath, content)); err != nil {
@@ -214,6 +326,7 @@ func (e *Extractor) saveSource(sourcePath, content string, tfh *os.File) {
 			}
 		}
 	}
+	return
 }
 
 //
@@ -241,8 +354,8 @@ func (e *Extractor) parseSources() error {
 	}
 	for _, s := range sources {
 		if str, ok := s.(string); ok && str != "" {
-			str = path.Join(e.dir, "sources", SanitizePath(str))
-			e.sources = append(e.sources, str)
+			e.raw = append(e.raw, str)
+			e.sources = append(e.sources, path.Join(e.dir, "sources", SanitizePath(str)))
 		}
 	}
 	return nil
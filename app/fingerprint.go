@@ -0,0 +1,100 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/semver"
+	"math"
+	"sort"
+)
+
+const (
+	EvidenceFingerprint = "fingerprint"
+
+	// fingerprintThreshold packages detected with a lower confidence get fingerprinted
+	fingerprintThreshold = 0.8
+)
+
+//
+// hashContent
+// @Description: Get the sha256 hash of a recovered file
+// @param content string
+// @return string
+func hashContent(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(h[:])
+}
+
+//
+// fingerprint
+// @Description: Find the published version whose tarball matches most of the recovered file hashes
+// @param n *npm.Npm
+// @param name string
+// @param hashes []string hashes of all recovered files of the package
+// @param limit int maximum number of candidate versions - 0 = all
+// @return *report.Evidence
+// @return error
+func fingerprint(n *npm.Npm, name string, hashes []string, limit int) (*report.Evidence, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	pkg, err := n.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]string, 0)
+	for _, v := range pkg.Versions() {
+		if sv, err := semver.Parse(v); err == nil && sv.IsPrerelease() == false {
+			candidates = append(candidates, v)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return semver.Compare(candidates[i], candidates[j]) > 0
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	var best *report.Evidence
+	for _, candidate := range candidates {
+		v := pkg.RepositoryVersions[candidate]
+		files, err := n.TarballHashes(&v)
+		if err != nil {
+			log.Warning("Failed to fingerprint %s@%s: %s", name, candidate, err.Error())
+			continue
+		}
+		known := map[string]bool{}
+		for _, h := range files {
+			known[h] = true
+		}
+		matched := 0
+		for _, h := range hashes {
+			if known[h] {
+				matched++
+			}
+		}
+		if matched == 0 {
+			continue
+		}
+
+		score := float64(matched) / float64(len(hashes))
+		if best == nil || score > best.Confidence {
+			best = &report.Evidence{
+				Version:    candidate,
+				Kind:       EvidenceFingerprint,
+				File:       fmt.Sprintf("%s (%d/%d files matched)", v.Dist.Tarball, matched, len(hashes)),
+				Confidence: math.Round(score*100) / 100,
+			}
+		}
+		if matched == len(hashes) {
+			break
+		}
+	}
+
+	return best, nil
+}
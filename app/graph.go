@@ -0,0 +1,41 @@
+package app
+
+import (
+	"github.com/webklex/juck/npm"
+	"os"
+	"path"
+)
+
+//
+// writeGraph
+// @Description: Export the dependency graph as Graphviz DOT, GraphML and JSON
+// @receiver a *Application
+// @param g *npm.Graph
+// @return error
+func (a *Application) writeGraph(g *npm.Graph) error {
+	eg, err := g.Export(npm.ExportOptions{
+		Depth:  a.GraphDepth,
+		Filter: a.GraphFilter,
+	})
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		npm.FormatDot:     "dependencies.dot",
+		npm.FormatGraphML: "dependencies.graphml",
+		npm.FormatJson:    "dependencies.graph.json",
+	}
+	for format, filename := range files {
+		fh, err := os.OpenFile(path.Join(a.OutputDir, filename), os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		err = eg.Write(fh, format)
+		_ = fh.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"encoding/csv"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//
+// packageMetadata
+// @Description: Fetch the license, homepage, repository, author and maintainers of a package. The license of
+// the detected version is preferred over the one of the package
+// @param n *npm.Npm
+// @param name string
+// @param version string
+// @return *report.Metadata
+// @return error
+func packageMetadata(n *npm.Npm, name, version string) (*report.Metadata, error) {
+	res, err := n.GetFull(name)
+	if err != nil {
+		return nil, err
+	}
+	m := &report.Metadata{
+		License:     res.License(),
+		Homepage:    res.Homepage,
+		Repository:  res.Url(),
+		Author:      res.Author(),
+		Maintainers: res.MaintainerNames(),
+	}
+	if v, ok := res.RepositoryVersions[version]; ok {
+		if v.License != "" {
+			m.License = string(v.License)
+		}
+		if m.Author == "" {
+			m.Author = v.Author.Name
+		}
+	}
+	return m, nil
+}
+
+//
+// enrichPackages
+// @Description: Add the registry metadata and recovered files to all discovered packages
+// @param n *npm.Npm
+// @param packages []*report.Package
+// @param files map[string][]string recovered files grouped by package name
+func enrichPackages(n *npm.Npm, packages []*report.Package, files map[string][]string) {
+	for _, p := range packages {
+		p.Files = files[p.Name]
+		p.FileCount = len(p.Files)
+		if p.Classification != ClassificationPublic {
+			continue
+		}
+		m, err := packageMetadata(n, p.Name, p.Version)
+		if err != nil {
+			log.Warning("Failed to fetch metadata of %s: %s", p.Name, strings.TrimSpace(err.Error()))
+			continue
+		}
+		p.Metadata = m
+	}
+}
+
+//
+// writePackagesCsv
+// @Description: Write all discovered packages including their metadata and recovered file count as csv
+// @param filename string
+// @param packages []*report.Package
+// @return error
+func writePackagesCsv(filename string, packages []*report.Package) error {
+	fh, err := os.OpenFile(filename, os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	w := csv.NewWriter(fh)
+	_ = w.Write([]string{"name", "version", "classification", "license", "homepage", "repository", "author", "maintainers", "file_count", "files"})
+	for _, p := range packages {
+		m := p.Metadata
+		if m == nil {
+			m = &report.Metadata{}
+		}
+		_ = w.Write([]string{
+			p.Name,
+			p.Version,
+			p.Classification,
+			m.License,
+			m.Homepage,
+			m.Repository,
+			m.Author,
+			strings.Join(m.Maintainers, ";"),
+			strconv.Itoa(p.FileCount),
+			strings.Join(p.Files, ";"),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
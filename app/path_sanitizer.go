@@ -12,6 +12,8 @@ import (
 // @param str string
 // @return string
 func SanitizePath(str string) string {
+	// Rollup and Vite prefix virtual modules with a null byte
+	str = strings.Replace(str, "\x00", "", -1)
 	if u, err := url.Parse(str); err == nil && u.Path != "" {
 		str = u.Path
 	}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	LayoutNodeModules = "node_modules"
+	LayoutPnpm        = "pnpm"
+	LayoutYarnPnP     = "yarn-pnp"
+	LayoutBower       = "bower"
+	LayoutJspm        = "jspm"
+	LayoutCdn         = "cdn"
+	// LayoutStylesheet packages are referenced by stylesheet imports (~bootstrap/scss/bootstrap)
+	LayoutStylesheet = "stylesheet"
+)
+
+// PackageRef is a package referenced by a source path. The version is only known for some layouts
+type PackageRef struct {
+	Name    string
+	Version string
+	Layout  string
+}
+
+// Recognizer extracts the package a source map source path belongs to
+type Recognizer interface {
+	Recognize(source string) *PackageRef
+}
+
+// RecognizerFunc allows the use of ordinary functions as Recognizer
+type RecognizerFunc func(source string) *PackageRef
+
+type regexRecognizer struct {
+	layout string
+	regex  *regexp.Regexp
+}
+
+const (
+	namePattern  = `(?P<name>(?:@[^/@]+/)?[^/@?#]+)`
+	scopePattern = `(?:^|/)`
+	// hostPattern anchors a host to the start of the source, optionally preceded by a scheme (https://, //)
+	hostPattern = `^(?:(?:[A-Za-z][A-Za-z0-9+.-]*:)?//)?`
+)
+
+var recognizers = []Recognizer{
+	MustRegexRecognizer(LayoutCdn, hostPattern+`esm\.sh/(?:v\d+/|stable/)?\*?`+namePattern+`(?:@(?P<version>[^/?#&]+))?`),
+	MustRegexRecognizer(LayoutCdn, hostPattern+`unpkg\.com/`+namePattern+`(?:@(?P<version>[^/?#]+))?`),
+	MustRegexRecognizer(LayoutCdn, hostPattern+`cdn\.jsdelivr\.net/npm/`+namePattern+`(?:@(?P<version>[^/?#]+))?`),
+	// Pinned skypack urls contain a hash: /-/react@v17.0.1-yQtjtVeleREmPpiTkf7H/dist=es2020/react.js
+	MustRegexRecognizer(LayoutCdn, hostPattern+`cdn\.skypack\.dev/(?:-/)?`+namePattern+`(?:@v?(?P<version>[^/?#]+?)(?:-[0-9A-Za-z]{20})?)?(?:[/?#]|$)`),
+	MustRegexRecognizer(LayoutCdn, hostPattern+`ga\.jspm\.io/npm:`+namePattern+`@(?P<version>[^/?#]+)`),
+	MustRegexRecognizer(LayoutJspm, scopePattern+`jspm_packages/npm/`+namePattern+`@(?P<version>[^/]+)`),
+	// .yarn/cache/lodash-npm-4.17.21-6382451519-eb835a2e51.zip/node_modules/lodash/lodash.js
+	MustRegexRecognizer(LayoutYarnPnP, scopePattern+`\.yarn/(?:cache|unplugged)/[^/]+?-npm-(?P<version>\d[^/]*?)(?:-[0-9a-f]{10})+(?:\.zip)?/node_modules/`+namePattern),
+	RecognizerFunc(recognizePnpm),
+	MustRegexRecognizer(LayoutBower, scopePattern+`bower_components/(?P<name>[^/]+)`),
+	RecognizerFunc(recognizeNodeModules),
+}
+
+// pnpmPathRegex matches node_modules/.pnpm/@scope+name@1.2.3_peer@4.5.6/node_modules/@scope/name/..
+var pnpmPathRegex = regexp.MustCompile(`(?:^|/)\.pnpm/((?:@[^/@+]+\+)?[^/@]+)@([^/_(]+)[^/]*/node_modules/((?:@[^/@]+/)?[^/@]+)`)
+
+// nestedPathRegex matches nested layouts like ../name@1.2.3/node_modules/name/..
+var nestedPathRegex = regexp.MustCompile(`(?:^|/)((?:@[^/@]+/)?[^/@]+)@(\d[^/_(]*)/node_modules/$`)
+
+//
+// RegisterRecognizer
+// @Description: Register an additional Recognizer. Registered recognizers take precedence over the built-in ones
+// and have to be registered before the application runs
+// @param r Recognizer
+func RegisterRecognizer(r Recognizer) {
+	recognizers = append([]Recognizer{r}, recognizers...)
+}
+
+//
+// MustRegexRecognizer
+// @Description: Create a new Recognizer based on a regular expression containing a "name" and an optional
+// "version" group. The last (innermost) match wins. It panics if the expression can't be compiled
+// @param layout string
+// @param pattern string
+// @return Recognizer
+func MustRegexRecognizer(layout, pattern string) Recognizer {
+	return &regexRecognizer{
+		layout: layout,
+		regex:  regexp.MustCompile(pattern),
+	}
+}
+
+//
+// recognizePackage
+// @Description: Get the package a given source path belongs to
+// @param source string
+// @return *PackageRef nil if the source doesn't belong to any known package layout
+func recognizePackage(source string) *PackageRef {
+	for _, r := range recognizers {
+		if ref := r.Recognize(source); ref != nil && ref.Name != "" {
+			return ref
+		}
+	}
+	return nil
+}
+
+//
+// Recognize
+// @Description: Call the underlying function
+// @receiver f RecognizerFunc
+// @param source string
+// @return *PackageRef
+func (f RecognizerFunc) Recognize(source string) *PackageRef {
+	return f(source)
+}
+
+//
+// Recognize
+// @Description: Get the package of the last match
+// @receiver r *regexRecognizer
+// @param source string
+// @return *PackageRef
+func (r *regexRecognizer) Recognize(source string) *PackageRef {
+	matches := r.regex.FindAllStringSubmatch(source, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	m := matches[len(matches)-1]
+	ref := &PackageRef{Layout: r.layout}
+	for i, group := range r.regex.SubexpNames() {
+		switch group {
+		case "name":
+			ref.Name = m[i]
+		case "version":
+			ref.Version = m[i]
+		}
+	}
+	return ref
+}
+
+//
+// recognizePnpm
+// @Description: Get the package of the innermost pnpm store folder. Dependencies are linked next to the package
+// itself, hence the version only applies if the linked name matches the store folder
+// @param source string
+// @return *PackageRef
+func recognizePnpm(source string) *PackageRef {
+	matches := pnpmPathRegex.FindAllStringSubmatch(source, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	m := matches[len(matches)-1]
+	ref := &PackageRef{Name: m[3], Layout: LayoutPnpm}
+	if strings.Replace(m[1], "+", "/", 1) == ref.Name {
+		ref.Version = m[2]
+	}
+	return ref
+}
+
+//
+// recognizeNodeModules
+// @Description: Get the package of the innermost node_modules folder - nested layouts contain several
+// @param source string
+// @return *PackageRef
+func recognizeNodeModules(source string) *PackageRef {
+	i := strings.LastIndex(source, "node_modules/")
+	if i < 0 || len(source) <= i+13 {
+		return nil
+	}
+	parts := strings.SplitN(source[i+13:], "/", 3)
+	ref := &PackageRef{Name: parts[0], Layout: LayoutNodeModules}
+	if strings.HasPrefix(parts[0], "@") && len(parts) > 1 {
+		ref.Name = parts[0] + "/" + parts[1]
+	}
+	if m := nestedPathRegex.FindStringSubmatch(source[:i+13]); m != nil && m[1] == ref.Name {
+		ref.Version = m[2]
+	}
+	return ref
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecognizePackage(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected *PackageRef
+	}{
+		// cdn
+		{"https://esm.sh/react@18.2.0/index.js", &PackageRef{Name: "react", Version: "18.2.0", Layout: LayoutCdn}},
+		{"https://esm.sh/v135/@lit/reactive-element@1.6.3/es2022/reactive-element.mjs", &PackageRef{Name: "@lit/reactive-element", Version: "1.6.3", Layout: LayoutCdn}},
+		{"https://esm.sh/stable/preact@10.19.2", &PackageRef{Name: "preact", Version: "10.19.2", Layout: LayoutCdn}},
+		{"https://unpkg.com/lodash@4.17.21/lodash.js", &PackageRef{Name: "lodash", Version: "4.17.21", Layout: LayoutCdn}},
+		{"//unpkg.com/@popperjs/core@2.11.8/dist/umd/popper.js", &PackageRef{Name: "@popperjs/core", Version: "2.11.8", Layout: LayoutCdn}},
+		{"unpkg.com/vue", &PackageRef{Name: "vue", Layout: LayoutCdn}},
+		{"https://cdn.jsdelivr.net/npm/bootstrap@5.3.2/dist/js/bootstrap.js", &PackageRef{Name: "bootstrap", Version: "5.3.2", Layout: LayoutCdn}},
+		{"https://cdn.skypack.dev/-/react@v17.0.1-yQtjtVeleREmPpiTkf7H/dist=es2020/react.js", &PackageRef{Name: "react", Version: "17.0.1", Layout: LayoutCdn}},
+		{"https://cdn.skypack.dev/canvas-confetti", &PackageRef{Name: "canvas-confetti", Layout: LayoutCdn}},
+		{"https://ga.jspm.io/npm:lit-html@2.8.0/lit-html.js", &PackageRef{Name: "lit-html", Version: "2.8.0", Layout: LayoutCdn}},
+		// cdn hosts are only recognized at the start of the source
+		{"webpack:///./src/components/unpkg.com/thing.js", nil},
+		{"webpack:///./src/app.js?https://unpkg.com/x", nil},
+		{"src/vendor/cdn.jsdelivr.net/npm/thing.js", nil},
+		{"webpack:///./src/esm.sh/react.js", nil},
+		// jspm
+		{"jspm_packages/npm/react@16.14.0/index.js", &PackageRef{Name: "react", Version: "16.14.0", Layout: LayoutJspm}},
+		{"../jspm_packages/npm/@babel/runtime@7.0.0/helpers/extends.js", &PackageRef{Name: "@babel/runtime", Version: "7.0.0", Layout: LayoutJspm}},
+		// yarn pnp
+		{".yarn/cache/lodash-npm-4.17.21-6382451519-eb835a2e51.zip/node_modules/lodash/lodash.js", &PackageRef{Name: "lodash", Version: "4.17.21", Layout: LayoutYarnPnP}},
+		{"webpack:///../.yarn/cache/@babel-runtime-npm-7.23.2-d0fb3ea5f7-6e4f5a2d8c.zip/node_modules/@babel/runtime/helpers/esm/extends.js", &PackageRef{Name: "@babel/runtime", Version: "7.23.2", Layout: LayoutYarnPnP}},
+		{".yarn/unplugged/esbuild-npm-0.19.5-1c1e9c9e4b/node_modules/esbuild/lib/main.js", &PackageRef{Name: "esbuild", Version: "0.19.5", Layout: LayoutYarnPnP}},
+		// pnpm
+		{"node_modules/.pnpm/react-dom@18.2.0_react@18.2.0/node_modules/react-dom/index.js", &PackageRef{Name: "react-dom", Version: "18.2.0", Layout: LayoutPnpm}},
+		{"node_modules/.pnpm/@vue+shared@3.3.8/node_modules/@vue/shared/dist/shared.esm-bundler.js", &PackageRef{Name: "@vue/shared", Version: "3.3.8", Layout: LayoutPnpm}},
+		{"node_modules/.pnpm/react-dom@18.2.0_react@18.2.0/node_modules/scheduler/index.js", &PackageRef{Name: "scheduler", Layout: LayoutPnpm}},
+		// bower
+		{"bower_components/jquery/dist/jquery.js", &PackageRef{Name: "jquery", Layout: LayoutBower}},
+		{"webpack:///./app/bower_components/angular/angular.js", &PackageRef{Name: "angular", Layout: LayoutBower}},
+		// node_modules
+		{"webpack:///./node_modules/axios/lib/axios.js", &PackageRef{Name: "axios", Layout: LayoutNodeModules}},
+		{"node_modules/@angular/core/fesm2022/core.mjs", &PackageRef{Name: "@angular/core", Layout: LayoutNodeModules}},
+		{"node_modules/a/node_modules/b@1.2.3/node_modules/b/index.js", &PackageRef{Name: "b", Version: "1.2.3", Layout: LayoutNodeModules}},
+		{"node_modules/a/node_modules/c/index.js", &PackageRef{Name: "c", Layout: LayoutNodeModules}},
+		// local sources
+		{"webpack:///./src/app.js", nil},
+		{"src/node_modules_helper.js", nil},
+		{"node_modules/", nil},
+	}
+	for _, tt := range tests {
+		if ref := recognizePackage(tt.source); !reflect.DeepEqual(ref, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.source, tt.expected, ref)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//
+// newRegistry
+// @Description: Create a npm registry client configured by the user and project .npmrc files and the given flags
+// (in ascending precedence)
+// @receiver a *Application
+// @return *npm.Npm
+// @return error
+func (a *Application) newRegistry() (*npm.Npm, error) {
+	n := npm.NewNpmRegistry()
+	n.SetTarballCache(a.TarballCache)
+	n.SetOffline(a.Offline)
+	n.SetWorkers(a.Workers)
+	kinds := make([]string, 0)
+	for _, kind := range strings.Split(a.DependencyKinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	if err := n.SetDependencyKinds(kinds); err != nil {
+		return nil, err
+	}
+	if a.NpmCache != "" {
+		n.SetDiskCache(npm.NewDiskCache(a.NpmCache, a.CacheTTL))
+	}
+	c := n.Config()
+
+	npmrc := make([]string, 0)
+	if home, err := os.UserHomeDir(); err == nil {
+		npmrc = append(npmrc, filepath.Join(home, ".npmrc"))
+	}
+	if dir, err := os.Getwd(); err == nil {
+		npmrc = append(npmrc, filepath.Join(dir, ".npmrc"))
+	}
+	for _, filename := range npmrc {
+		if err := c.LoadNpmrc(filename); err == nil {
+			log.Info("Loaded npm config: %s", filename)
+		} else if errors.Is(err, os.ErrNotExist) == false {
+			log.Warning("Failed to load npm config \"%s\": %s", filename, err.Error())
+		}
+	}
+	if a.Npmrc != "" {
+		if err := c.LoadNpmrc(a.Npmrc); err != nil {
+			return nil, err
+		}
+		log.Info("Loaded npm config: %s", a.Npmrc)
+	}
+
+	if a.Registry != "" {
+		c.SetRegistry(a.Registry)
+	}
+	c.SetPublicRegistry(a.PublicRegistry)
+	if a.ScopeRegistries != "" {
+		for _, entry := range strings.Split(a.ScopeRegistries, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("invalid scope registry \"%s\". expected format: @scope=url", entry)
+			}
+			c.SetScopeRegistry(parts[0], parts[1])
+		}
+	}
+	if a.RegistryToken != "" {
+		c.SetToken(c.Registry, a.RegistryToken)
+	}
+
+	return n, nil
+}
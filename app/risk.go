@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
+	"strings"
+	"time"
+)
+
+const (
+	RiskDeprecated  = "deprecated"
+	RiskUnpublished = "unpublished"
+	RiskAbandoned   = "abandoned"
+)
+
+//
+// assessRisks
+// @Description: Check a discovered package for deprecation, removal from the registry and its publish activity.
+// Abbreviated documents contain the deprecation of every version but no time data - the full document is only
+// requested if publish activity or unpublished versions have to be checked
+// @param n *npm.Npm
+// @param p *report.Package
+// @param abandonedAfter time.Duration packages without a publish within this duration are flagged (0 = disabled)
+// @return []*report.Risk
+func assessRisks(n *npm.Npm, p *report.Package, abandonedAfter time.Duration) []*report.Risk {
+	res, err := n.Get(p.Name)
+	if err != nil {
+		log.Warning("Failed to assess %s: %s", p.Name, strings.TrimSpace(err.Error()))
+		return nil
+	}
+	// Unpublished packages don't have any versions left
+	_, known := res.RepositoryVersions[p.Version]
+	if res.IsAbbreviated() && (abandonedAfter > 0 || len(res.RepositoryVersions) == 0 || (p.Version != "" && !known)) {
+		if full, err := n.GetFull(p.Name); err == nil {
+			res = full
+		} else {
+			log.Warning("Failed to get the publish activity of %s: %s", p.Name, strings.TrimSpace(err.Error()))
+		}
+	}
+
+	var risks []*report.Risk
+	if t, ok := res.Unpublished(); ok {
+		risks = append(risks, &report.Risk{
+			Package: p.Name,
+			Version: p.Version,
+			Kind:    RiskUnpublished,
+			Message: fmt.Sprintf("package has been unpublished on %s", t.Format("2006-01-02")),
+		})
+		return risks
+	}
+	if p.Version != "" && res.VersionUnpublished(p.Version) {
+		risks = append(risks, &report.Risk{
+			Package: p.Name,
+			Version: p.Version,
+			Kind:    RiskUnpublished,
+			Message: fmt.Sprintf("version %s has been unpublished", p.Version),
+		})
+	}
+	if msg := res.Deprecated(p.Version); msg != "" {
+		risks = append(risks, &report.Risk{
+			Package: p.Name,
+			Version: p.Version,
+			Kind:    RiskDeprecated,
+			Message: msg,
+		})
+	}
+	if last := res.LastPublish(); abandonedAfter > 0 && !last.IsZero() && time.Since(last) > abandonedAfter {
+		risks = append(risks, &report.Risk{
+			Package:     p.Name,
+			Version:     p.Version,
+			Kind:        RiskAbandoned,
+			Message:     fmt.Sprintf("last version has been published on %s", last.Format("2006-01-02")),
+			LastPublish: &last,
+		})
+	}
+	return risks
+}
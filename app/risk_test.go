@@ -0,0 +1,65 @@
+package app
+
+import (
+	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAssessRisks(t *testing.T) {
+	abbreviated := `{"name":"left-pad","dist-tags":{"latest":"1.3.0"},"versions":{
+		"1.2.0":{"name":"left-pad","version":"1.2.0"},
+		"1.3.0":{"name":"left-pad","version":"1.3.0","deprecated":"use String.prototype.padStart()"}}}`
+	full := `{"name":"left-pad","dist-tags":{"latest":"1.3.0"},"versions":{
+		"1.2.0":{"name":"left-pad","version":"1.2.0"},
+		"1.3.0":{"name":"left-pad","version":"1.3.0","deprecated":"use String.prototype.padStart()"}},
+		"time":{"created":"2014-03-14T00:00:00.000Z","modified":"2022-06-19T00:00:00.000Z",
+		"1.1.0":"2016-01-01T00:00:00.000Z","1.2.0":"2017-01-01T00:00:00.000Z","1.3.0":"2018-04-09T00:00:00.000Z"}}`
+
+	var fullRequests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Accept"), "application/vnd.npm.install-v1+json") {
+			_, _ = w.Write([]byte(abbreviated))
+			return
+		}
+		atomic.AddInt32(&fullRequests, 1)
+		_, _ = w.Write([]byte(full))
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name           string
+		version        string
+		abandonedAfter time.Duration
+		kinds          []string
+		fullDocument   bool
+	}{
+		{"deprecated", "1.3.0", 0, []string{RiskDeprecated}, false},
+		{"not deprecated", "1.2.0", 0, nil, false},
+		{"abandoned", "1.3.0", 365 * 24 * time.Hour, []string{RiskDeprecated, RiskAbandoned}, true},
+		{"unpublished version", "1.1.0", 0, []string{RiskUnpublished, RiskDeprecated}, true},
+	}
+	for _, tt := range tests {
+		n := npm.NewNpmRegistry()
+		// Every case gets its own registry url - documents are cached per registry
+		n.SetRegistry(s.URL + "/" + strings.ReplaceAll(tt.name, " ", "-") + "/")
+		atomic.StoreInt32(&fullRequests, 0)
+
+		risks := assessRisks(n, &report.Package{Name: "left-pad", Version: tt.version}, tt.abandonedAfter)
+		kinds := make([]string, 0)
+		for _, r := range risks {
+			kinds = append(kinds, r.Kind)
+		}
+		if strings.Join(kinds, ",") != strings.Join(tt.kinds, ",") {
+			t.Errorf("%s: expected risks %v, got %v", tt.name, tt.kinds, kinds)
+		}
+		if requested := atomic.LoadInt32(&fullRequests) > 0; requested != tt.fullDocument {
+			t.Errorf("%s: expected the full document to be requested: %v, got %v", tt.name, tt.fullDocument, requested)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"github.com/webklex/juck/log"
+	"github.com/webklex/juck/npm"
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/utils"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	ImportUse      = "use"
+	ImportForward  = "forward"
+	ImportImport   = "import"
+	ImportRequire  = "require"
+	ImportTailwind = "tailwind"
+)
+
+var (
+	styleExtensions = []string{".css", ".scss", ".sass", ".less", ".styl"}
+	// styleLanguages maps the lang attribute of Vue / Svelte style blocks to an extension
+	styleLanguages = map[string]string{
+		"css":     ".css",
+		"scss":    ".scss",
+		"sass":    ".sass",
+		"less":    ".less",
+		"styl":    ".styl",
+		"stylus":  ".styl",
+		"postcss": ".css",
+	}
+	styleImportRegex    = regexp.MustCompile(`(?m)@(use|forward|import|require)\s+([^;{}\n]+)`)
+	styleSpecifierRegex = regexp.MustCompile(`^\s*(?:\([\w\s,]+\)\s*)?(?:url\(\s*)?["']?([^"'()\s,;]+)["']?`)
+	quotedRegex         = regexp.MustCompile(`["']([^"']+)["']`)
+	tailwindRegex       = regexp.MustCompile(`@tailwind\s+(base|components|utilities|variants|screens)\b`)
+)
+
+// styleFile is a recovered stylesheet waiting for its imports to be resolved
+type styleFile struct {
+	// module is the sanitized source path relative to the sources folder
+	module  string
+	content string
+}
+
+//
+// isStylesheetMap
+// @Description: Check if the extracted source map belongs to a stylesheet
+// @receiver e *Extractor
+// @param filename string
+// @return bool
+func (e *Extractor) isStylesheetMap(filename string) bool {
+	if tf, ok := e.data["file"].(string); ok && strings.HasSuffix(SanitizePath(tf), ".css") {
+		return true
+	}
+	if strings.HasSuffix(filename, ".css.map") || strings.HasSuffix(e.sourceMap, ".css.map") {
+		return true
+	}
+	for _, s := range e.sources {
+		if !hasStyleExtension(s) {
+			return false
+		}
+	}
+	return len(e.sources) > 0
+}
+
+//
+// stylesheetPath
+// @Description: Get the path of a stylesheet source. Sources without a stylesheet extension (e.g. Vue style blocks)
+// get the extension of their lang query parameter (App.vue?vue&type=style&lang=scss -> App.vue.scss) or .css
+// @param sourcePath string
+// @param raw string unsanitized source path
+// @return string
+func stylesheetPath(sourcePath, raw string) string {
+	if hasStyleExtension(sourcePath) {
+		return sourcePath
+	}
+	ext := ".css"
+	if i := strings.Index(raw, "?"); i >= 0 {
+		if q, err := url.ParseQuery(raw[i+1:]); err == nil {
+			if e, ok := styleLanguages[strings.ToLower(q.Get("lang"))]; ok {
+				ext = e
+			}
+		}
+	}
+	return sourcePath + ext
+}
+
+//
+// hasStyleExtension
+// @Description: Check if a given path has a stylesheet extension
+// @param p string
+// @return bool
+func hasStyleExtension(p string) bool {
+	return utils.InStringList(styleExtensions, strings.ToLower(path.Ext(p)))
+}
+
+//
+// resolveStyleImports
+// @Description: Resolve the @use, @forward, @import and @require references of all recovered stylesheets against
+// the recovered sources. Referenced npm packages (~bootstrap/scss/bootstrap, node_modules/bootstrap/scss/_grid.scss,
+// @tailwind base) are returned. Unresolved references without a ~ prefix are local files, which weren't recovered
+// @receiver e *Extractor
+// @return nodeModules []string
+func (e *Extractor) resolveStyleImports() (nodeModules []string) {
+	known := map[string]bool{}
+	// packages maps the referenced package names to the names verified by the registry
+	packages := map[string]string{}
+	for _, s := range e.styles {
+		known[s.module] = true
+	}
+	for _, s := range e.styles {
+		from := path.Join("sources", s.module)
+		for _, ref := range parseStyleImports(s.content) {
+			imp := &report.StyleImport{
+				From:      from,
+				To:        ref.specifier,
+				Kind:      ref.kind,
+				Specifier: ref.specifier,
+			}
+			if ref.kind == ImportTailwind {
+				imp.To, imp.Package = "tailwindcss", "tailwindcss"
+			} else if target := resolveStyleImport(s.module, ref.specifier, known); target != "" {
+				imp.To = path.Join("sources", target)
+				if p := recognizePackage(target); p != nil && isNodeModulesPath(target) {
+					imp.Package = p.Name
+				}
+			} else {
+				imp.Package = stylePackage(ref.specifier)
+			}
+			if imp.Package != "" && npm.ValidateName(imp.Package) != nil {
+				imp.Package = ""
+			}
+			if imp.Package != "" {
+				name, ok := packages[imp.Package]
+				if !ok {
+					var registered bool
+					name, registered = e.getModuleName(&PackageRef{Name: imp.Package, Layout: LayoutStylesheet})
+					packages[imp.Package] = name
+					nodeModules = append(nodeModules, name)
+					if registered {
+						e.registered[name] = true
+					}
+				}
+				imp.Package = name
+			}
+			e.imports = append(e.imports, imp)
+		}
+	}
+	if len(e.imports) > 0 {
+		log.Statistic("Discovered stylesheet imports: %d", len(e.imports))
+	}
+	return
+}
+
+// styleRef is a single stylesheet reference
+type styleRef struct {
+	kind      string
+	specifier string
+}
+
+//
+// parseStyleImports
+// @Description: Get all stylesheet references of a given content. Remote urls and Sass built-in modules are skipped
+// @param content string
+// @return []*styleRef
+func parseStyleImports(content string) []*styleRef {
+	var refs []*styleRef
+	for _, m := range styleImportRegex.FindAllStringSubmatch(content, -1) {
+		specifiers := make([]string, 0)
+		if m[1] == ImportImport || m[1] == ImportRequire {
+			// @import "a", "b";
+			for _, q := range quotedRegex.FindAllStringSubmatch(m[2], -1) {
+				specifiers = append(specifiers, q[1])
+			}
+		}
+		if len(specifiers) == 0 {
+			if s := styleSpecifierRegex.FindStringSubmatch(m[2]); s != nil {
+				specifiers = append(specifiers, s[1])
+			}
+		}
+		for _, s := range specifiers {
+			if strings.HasPrefix(s, "sass:") || strings.HasPrefix(s, "//") || strings.Contains(s, "://") || strings.HasPrefix(s, "data:") {
+				continue
+			}
+			refs = append(refs, &styleRef{kind: m[1], specifier: s})
+		}
+	}
+	for _, m := range tailwindRegex.FindAllStringSubmatch(content, -1) {
+		refs = append(refs, &styleRef{kind: ImportTailwind, specifier: m[1]})
+	}
+	return refs
+}
+
+//
+// resolveStyleImport
+// @Description: Resolve a stylesheet reference the way Sass, Less and Stylus do: relative to the importing file,
+// relative to the root and within node_modules - including partials (_name.scss) and index files
+// @param module string path of the importing file relative to the sources folder
+// @param specifier string
+// @param known map[string]bool paths of all recovered stylesheets relative to the sources folder
+// @return string empty if the reference couldn't be resolved
+func resolveStyleImport(module, specifier string, known map[string]bool) string {
+	specifier = strings.TrimPrefix(specifier, "~")
+	bases := []string{path.Join(path.Dir(module), specifier), path.Clean(specifier), path.Join("node_modules", specifier)}
+	if strings.HasPrefix(specifier, "/") {
+		bases = []string{strings.TrimPrefix(path.Clean(specifier), "/")}
+	}
+	for _, base := range bases {
+		dir, name := path.Split(base)
+		candidates := []string{base}
+		for _, ext := range styleExtensions {
+			candidates = append(candidates,
+				base+ext,
+				path.Join(dir, "_"+name+ext),
+				path.Join(base, "_index"+ext),
+				path.Join(base, "index"+ext),
+			)
+		}
+		for _, c := range candidates {
+			if known[c] {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+//
+// isNodeModulesPath
+// @Description: Check if a given path lies within a node_modules folder
+// @param p string
+// @return bool
+func isNodeModulesPath(p string) bool {
+	return strings.HasPrefix(p, "node_modules/") || strings.Contains(p, "/node_modules/")
+}
+
+//
+// stylePackage
+// @Description: Get the npm package of an unresolved stylesheet reference. Only ~ prefixed references
+// (~bootstrap/scss/functions) are considered to be packages - references such as abstracts/variables are usually
+// local partials
+// @param specifier string
+// @return string
+func stylePackage(specifier string) string {
+	if !strings.HasPrefix(specifier, "~") {
+		return ""
+	}
+	specifier = strings.TrimPrefix(specifier, "~")
+	if specifier == "" || strings.HasPrefix(specifier, ".") || strings.HasPrefix(specifier, "/") {
+		return ""
+	}
+	parts := strings.SplitN(specifier, "/", 3)
+	name := parts[0]
+	if strings.HasPrefix(name, "@") {
+		if len(parts) < 2 {
+			return ""
+		}
+		name += "/" + parts[1]
+	}
+	// Sass partials and files are never packages
+	if strings.HasPrefix(name, "_") || hasStyleExtension(name) {
+		return ""
+	}
+	return name
+}
+
+//
+// addStylesheet
+// @Description: Remember a recovered stylesheet in order to resolve its imports once all sources are recovered
+// @receiver e *Extractor
+// @param sourcePath string
+// @param content string
+func (e *Extractor) addStylesheet(sourcePath, content string) {
+	module, err := filepath.Rel(path.Join(e.dir, "sources"), sourcePath)
+	if err != nil || strings.HasPrefix(module, "..") {
+		return
+	}
+	e.styles = append(e.styles, &styleFile{module: filepath.ToSlash(module), content: content})
+}
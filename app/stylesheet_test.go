@@ -0,0 +1,80 @@
+package app
+
+import (
+	"github.com/webklex/juck/report"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStylePackage(t *testing.T) {
+	tests := map[string]string{
+		"~bootstrap/scss/bootstrap":  "bootstrap",
+		"~bootstrap":                 "bootstrap",
+		"~@fontsource/inter/400.css": "@fontsource/inter",
+		"~@scope":                    "",
+		"~./local":                   "",
+		"~_partial":                  "",
+		"~variables.scss":            "",
+		"abstracts/variables":        "",
+		"bootstrap/scss/functions":   "",
+		"tailwindcss":                "",
+		"@fontsource/inter/400.css":  "",
+		"./base/reset":               "",
+		"/assets/styles/theme":       "",
+		"":                           "",
+	}
+	for specifier, expected := range tests {
+		if name := stylePackage(specifier); name != expected {
+			t.Errorf("%s: expected \"%s\", got \"%s\"", specifier, expected, name)
+		}
+	}
+}
+
+func TestResolveStyleImports(t *testing.T) {
+	e := NewExtractor(t.TempDir())
+	e.npm.SetOffline(true)
+	e.styles = []*styleFile{
+		{module: "src/main.scss", content: "@import 'abstracts/variables';\n@use 'base/reset';\n@import '~bootstrap/scss/bootstrap';\n@use 'sass:math';\n@import 'node_modules/normalize.css/normalize';\n@tailwind base;\n"},
+		{module: "src/base/_reset.scss", content: "body { margin: 0; }"},
+		{module: "node_modules/normalize.css/normalize.css", content: "html { line-height: 1.15; }"},
+	}
+
+	nodeModules := e.resolveStyleImports()
+	if expected := []string{"bootstrap", "normalize.css", "tailwindcss"}; !reflect.DeepEqual(nodeModules, expected) {
+		t.Errorf("expected node modules %v, got %v", expected, nodeModules)
+	}
+
+	expected := []*report.StyleImport{
+		{From: "sources/src/main.scss", To: "abstracts/variables", Kind: ImportImport, Specifier: "abstracts/variables"},
+		{From: "sources/src/main.scss", To: "sources/src/base/_reset.scss", Kind: ImportUse, Specifier: "base/reset"},
+		{From: "sources/src/main.scss", To: "~bootstrap/scss/bootstrap", Kind: ImportImport, Specifier: "~bootstrap/scss/bootstrap", Package: "bootstrap"},
+		{From: "sources/src/main.scss", To: "sources/node_modules/normalize.css/normalize.css", Kind: ImportImport, Specifier: "node_modules/normalize.css/normalize", Package: "normalize.css"},
+		{From: "sources/src/main.scss", To: "tailwindcss", Kind: ImportTailwind, Specifier: "base", Package: "tailwindcss"},
+	}
+	if !reflect.DeepEqual(e.imports, expected) {
+		for _, imp := range e.imports {
+			t.Logf("%+v", imp)
+		}
+		t.Error("unexpected stylesheet imports")
+	}
+}
+
+func TestWriteStyleGraph(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stylesheets.dot")
+	imports := []*report.StyleImport{
+		{From: "sources/src/\"main\".scss", To: "sources/src/ü\x00.scss", Kind: ImportUse},
+	}
+	if err := writeStyleGraph(filename, imports); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\t\"sources/src/\\\"main\\\".scss\" -> \"sources/src/ü\x00.scss\" [label=\"use\"];\n") {
+		t.Errorf("unexpected DOT output:\n%s", data)
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/semver"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	EvidencePackageJson = "package.json"
+	EvidenceBanner      = "banner"
+	EvidenceConstant    = "constant"
+	EvidencePath        = "path"
+
+	// bannerHeadSize limits the banner search to the beginning of a file
+	bannerHeadSize = 4096
+)
+
+var (
+	versionPattern         = `v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`
+	packageJsonVersion     = regexp.MustCompile(`"version"\s*:\s*"` + versionPattern + `"`)
+	bannerCommentRegex     = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	bannerVersionRegex     = regexp.MustCompile(`(?:^|[\s@/(])` + versionPattern + `(?:$|[\s,;)*])`)
+	versionConstantRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\bVERSION\s*=\s*["']` + versionPattern + `["']`),
+		regexp.MustCompile(`\b(?:exports|\w+)\.version\s*=\s*["']` + versionPattern + `["']`),
+		regexp.MustCompile(`\bversion\s*:\s*["']` + versionPattern + `["']`),
+	}
+)
+
+//
+// detectVersions
+// @Description: Collect all evidence of the bundled version of a package found in a recovered file
+// @param name string
+// @param sourcePath string
+// @param file string
+// @param content string
+// @return []*report.Evidence
+func detectVersions(name, sourcePath, file, content string) (evidence []*report.Evidence) {
+	add := func(kind, version string, confidence float64) {
+		if v, err := semver.Parse(version); err == nil {
+			evidence = append(evidence, &report.Evidence{
+				Version:    v.String(),
+				Kind:       kind,
+				File:       file,
+				Confidence: confidence,
+			})
+		}
+	}
+
+	if filepath.Base(sourcePath) == "package.json" && strings.HasSuffix(filepath.Dir(sourcePath), "node_modules/"+name) {
+		var pkg struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal([]byte(content), &pkg); err == nil && pkg.Version != "" {
+			if pkg.Name == name {
+				add(EvidencePackageJson, pkg.Version, 0.95)
+			} else {
+				add(EvidencePackageJson, pkg.Version, 0.7)
+			}
+		} else if m := packageJsonVersion.FindStringSubmatch(content); m != nil {
+			// package.json fragment
+			add(EvidencePackageJson, m[1], 0.85)
+		}
+		return
+	}
+
+	head := content
+	if len(head) > bannerHeadSize {
+		head = head[:bannerHeadSize]
+	}
+	shortName := strings.ToLower(name[strings.LastIndex(name, "/")+1:])
+	for _, comment := range bannerCommentRegex.FindAllString(head, -1) {
+		m := bannerVersionRegex.FindStringSubmatch(comment)
+		if m == nil {
+			continue
+		}
+		if strings.Contains(strings.ToLower(comment), shortName) {
+			add(EvidenceBanner, m[1], 0.8)
+		} else if strings.HasPrefix(comment, "/*!") || strings.Contains(comment, "@license") || strings.Contains(comment, "@version") {
+			add(EvidenceBanner, m[1], 0.5)
+		}
+	}
+
+	for _, r := range versionConstantRegexes {
+		for _, m := range r.FindAllStringSubmatch(content, -1) {
+			add(EvidenceConstant, m[1], 0.6)
+		}
+	}
+
+	return
+}
+
+//
+// pathEvidence
+// @Description: Get the version evidence of a version contained within the package path (e.g. pnpm, yarn or cdn
+// layouts). Ranges like esm.sh/react@18 aren't exact versions and are ignored
+// @param ref *PackageRef
+// @param file string
+// @return *report.Evidence
+func pathEvidence(ref *PackageRef, file string) *report.Evidence {
+	v, err := semver.Parse(ref.Version)
+	if err != nil {
+		return nil
+	}
+	return &report.Evidence{
+		Version:    v.String(),
+		Kind:       EvidencePath,
+		File:       file,
+		Confidence: 0.9,
+	}
+}
+
+//
+// resolveVersion
+// @Description: Pick the most likely version based on the collected evidence. The confidence of the result is
+// the strongest evidence of the picked version weighted by its share of all evidence
+// @param evidence []*report.Evidence
+// @return version string
+// @return confidence float64
+func resolveVersion(evidence []*report.Evidence) (version string, confidence float64) {
+	scores := map[string]float64{}
+	strongest := map[string]float64{}
+	total := 0.0
+	for _, e := range evidence {
+		scores[e.Version] += e.Confidence
+		total += e.Confidence
+		if e.Confidence > strongest[e.Version] {
+			strongest[e.Version] = e.Confidence
+		}
+	}
+
+	versions := make([]string, 0, len(scores))
+	for v := range scores {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if scores[versions[i]] == scores[versions[j]] {
+			return semver.Compare(versions[i], versions[j]) > 0
+		}
+		return scores[versions[i]] > scores[versions[j]]
+	})
+	if len(versions) == 0 {
+		return "", 0
+	}
+
+	version = versions[0]
+	return version, math.Round(strongest[version]*scores[version]/total*100) / 100
+}
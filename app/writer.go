@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"github.com/webklex/juck/report"
+	"github.com/webklex/juck/utils"
+	"os"
+	"strings"
+)
+
+//
+// writeEndpoints
+// @Description: Write all endpoints grouped by host. Relative urls, routes and GraphQL operations are listed first
+// @param filename string
+// @param endpoints []*report.Endpoint sorted by host
+// @return error
+func writeEndpoints(filename string, endpoints []*report.Endpoint) error {
+	fh, err := os.OpenFile(filename, os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	host := "-"
+	for _, ep := range endpoints {
+		if ep.Host != host {
+			host = ep.Host
+			name := host
+			if name == "" {
+				name = "(relative)"
+			}
+			if _, err := fh.WriteString(fmt.Sprintf("%s\n", name)); err != nil {
+				return err
+			}
+		}
+		line := []string{"[" + ep.Kind + "]", ep.Url}
+		if ep.Method != "" {
+			line = []string{"[" + ep.Kind + "]", ep.Method, ep.Url}
+		}
+		if _, err := fh.WriteString(fmt.Sprintf("\t%s\n", strings.Join(line, " "))); err != nil {
+			return err
+		}
+		for _, f := range ep.Files {
+			if _, err := fh.WriteString(fmt.Sprintf("\t\t%s\n", f)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//
+// writeIndicators
+// @Description: Write all indicators grouped by kind including the files referencing them
+// @param filename string
+// @param indicators []*report.Indicator sorted by kind
+// @return error
+func writeIndicators(filename string, indicators []*report.Indicator) error {
+	fh, err := os.OpenFile(filename, os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	for _, i := range indicators {
+		if _, err := fh.WriteString(fmt.Sprintf("%s [%s]\n", i.Value, i.Kind)); err != nil {
+			return err
+		}
+		for _, f := range i.Files {
+			if _, err := fh.WriteString(fmt.Sprintf("\t%s\n", f)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//
+// writeFindings
+// @Description: Write the findings of all analyzers - one finding per line
+// @param filename string
+// @param findings []*report.Finding
+// @return error
+func writeFindings(filename string, findings []*report.Finding) error {
+	fh, err := os.OpenFile(filename, os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	for _, f := range findings {
+		location := f.File
+		if location == "" {
+			location = f.SourceMap
+		} else if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		line := []string{"[" + f.Analyzer + "]", "[" + f.Kind + "]", f.Value, location}
+		if f.Severity != "" {
+			line = append([]string{"[" + f.Severity + "]"}, line...)
+		}
+		if _, err := fh.WriteString(strings.Join(line, " ") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// writeStyleGraph
+// @Description: Write the stylesheet import graph as Graphviz DOT. Package nodes are filled
+// @param filename string
+// @param imports []*report.StyleImport
+// @return error
+func writeStyleGraph(filename string, imports []*report.StyleImport) error {
+	fh, err := os.OpenFile(filename, os.O_TRUNC|os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	b := &strings.Builder{}
+	b.WriteString("digraph stylesheets {\n")
+	b.WriteString("\trankdir=LR;\n\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	packages := map[string]bool{}
+	for _, i := range imports {
+		if i.Package != "" && !packages[i.Package] {
+			packages[i.Package] = true
+			b.WriteString(fmt.Sprintf("\t%s [shape=ellipse, style=filled, fillcolor=\"#9ecae1\"];\n", utils.DotQuote(i.Package)))
+		}
+	}
+	edges := map[string]bool{}
+	for _, i := range imports {
+		lines := []string{fmt.Sprintf("\t%s -> %s [label=%s];\n", utils.DotQuote(i.From), utils.DotQuote(i.To), utils.DotQuote(i.Kind))}
+		if i.Package != "" && i.To != i.Package {
+			lines = append(lines, fmt.Sprintf("\t%s -> %s [style=dashed];\n", utils.DotQuote(i.To), utils.DotQuote(i.Package)))
+		}
+		for _, l := range lines {
+			if !edges[l] {
+				edges[l] = true
+				b.WriteString(l)
+			}
+		}
+	}
+	b.WriteString("}\n")
+	_, err = fh.WriteString(b.String())
+	return err
+}
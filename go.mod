@@ -2,7 +2,10 @@ module github.com/webklex/juck
 
 go 1.18
 
-require github.com/fatih/color v1.13.0
+require (
+	github.com/fatih/color v1.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.9 // indirect
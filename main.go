@@ -7,6 +7,7 @@ import (
 	"github.com/webklex/juck/app"
 	"github.com/webklex/juck/log"
 	"os"
+	"strings"
 )
 
 var buildNumber string
@@ -15,6 +16,17 @@ var buildVersion string
 func main() {
 	a := app.NewApplication()
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "audit":
+			audit(a, os.Args[2:])
+			return
+		case "cache":
+			cache(a, os.Args[2:])
+			return
+		}
+	}
+
 	flag.CommandLine.StringVar(&a.OutputDir, "output", a.OutputDir, "Directory to output from sourcemap to")
 	flag.CommandLine.StringVar(&a.FileList, "file-list", a.FileList, "File path of a file containing a list of target source map file paths")
 	flag.CommandLine.StringVar(&a.UrlList, "url-list", a.UrlList, "File path of a file containing a list of target source map urls")
@@ -26,6 +38,28 @@ func main() {
 	flag.CommandLine.BoolVar(&a.Combined, "combined", a.Combined, "Combine all source files into one")
 	flag.CommandLine.BoolVar(&a.DisableSSL, "disable-ssl", a.DisableSSL, "Don't verify the site's SSL certificate")
 	flag.CommandLine.IntVar(&log.Mode, "log", log.Mode, "Set the log mode (0 = all, 1 = success, 2 = warning, 3 = statistic, 4 = error)")
+	flag.CommandLine.StringVar(&a.Registry, "registry", a.Registry, "Npm registry base url (e.g. a local mirror). Overrides the .npmrc registry")
+	flag.CommandLine.StringVar(&a.PublicRegistry, "public-registry", a.PublicRegistry, "Public npm registry base url used to detect dependency confusion candidates")
+	flag.CommandLine.StringVar(&a.ScopeRegistries, "scope-registry", a.ScopeRegistries, "Comma separated list of scope registries (e.g. @acme=https://npm.acme.com/)")
+	flag.CommandLine.StringVar(&a.RegistryToken, "registry-token", a.RegistryToken, "Auth token used for the default registry")
+	flag.CommandLine.StringVar(&a.Npmrc, "npmrc", a.Npmrc, "Additional .npmrc file to load (~/.npmrc and ./.npmrc are loaded by default)")
+	flag.CommandLine.BoolVar(&a.Fingerprint, "fingerprint", a.Fingerprint, "Pin package versions by matching recovered files against registry tarballs")
+	flag.CommandLine.IntVar(&a.FingerprintCandidates, "fingerprint-candidates", a.FingerprintCandidates, "Maximum number of (most recent) versions to fingerprint per package (0 = all)")
+	flag.CommandLine.StringVar(&a.TarballCache, "tarball-cache", a.TarballCache, "Directory used to cache downloaded tarballs")
+	flag.CommandLine.StringVar(&a.NpmCache, "npm-cache", a.NpmCache, "Directory used to cache registry metadata (empty = disabled)")
+	flag.CommandLine.DurationVar(&a.CacheTTL, "cache-ttl", a.CacheTTL, "Revalidate cached registry metadata older than the given duration")
+	flag.CommandLine.BoolVar(&a.Offline, "offline", a.Offline, "Only serve registry metadata and tarballs from the caches")
+	flag.CommandLine.IntVar(&a.Workers, "workers", a.Workers, "Maximum number of concurrent registry requests")
+	flag.CommandLine.StringVar(&a.DependencyKinds, "dependency-kinds", a.DependencyKinds, "Comma separated list of dependency kinds to follow (prod, peer, peer-optional, optional, dev)")
+	flag.CommandLine.IntVar(&a.GraphDepth, "graph-depth", a.GraphDepth, "Limit the exported dependency graph to the given depth (0 = unlimited)")
+	flag.CommandLine.StringVar(&a.GraphFilter, "graph-filter", a.GraphFilter, "Only export graph nodes matching the given glob pattern (e.g. @babel/*) and the nodes pulling them in")
+	flag.CommandLine.DurationVar(&a.AbandonedAfter, "abandoned-after", a.AbandonedAfter, "Flag packages without a publish within the given duration as abandoned (0 = disabled)")
+	flag.CommandLine.StringVar(&a.SecretRules, "secret-rules", a.SecretRules, "Yaml file containing custom secret rules and allowlists")
+	flag.CommandLine.BoolVar(&a.DisableSecrets, "disable-secrets", a.DisableSecrets, "Don't scan recovered sources for secrets")
+	flag.CommandLine.BoolVar(&a.VendorEndpoints, "vendor-endpoints", a.VendorEndpoints, "Also extract endpoints from node module sources")
+	flag.CommandLine.StringVar(&a.CommentKeywords, "comment-keywords", a.CommentKeywords, "Comma separated list of keywords a developer comment has to contain in order to be reported")
+	flag.CommandLine.StringVar(&a.Analyzers, "analyzers", a.Analyzers, "Comma separated list of analyzers to run (default: all configured or built-in analyzers)")
+	flag.CommandLine.StringVar(&a.Config, "config", a.Config, "Yaml config file containing the analyzers and their options")
 	flag.CommandLine.BoolVar(&a.DangerouslyWritePaths, "dangerously-write-paths", a.DangerouslyWritePaths, "Write full paths. WARNING: Be careful here, you are pulling directories from an untrusted source")
 
 	sv := flag.Bool("version", false, "Show version and exit")
@@ -45,3 +79,56 @@ func main() {
 		log.Error(err)
 	}
 }
+
+//
+// audit
+// @Description: Parse the audit command flags and match the discovered packages against an OSV dump
+// @param a *app.Application
+// @param args []string
+func audit(a *app.Application, args []string) {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	fs.StringVar(&a.OutputDir, "output", a.OutputDir, "Directory containing a previous juck output")
+	fs.StringVar(&a.OsvDatabase, "db", a.OsvDatabase, "OSV database dump (directory or zip file of OSV json files)")
+	fs.IntVar(&log.Mode, "log", log.Mode, "Set the log mode (0 = all, 1 = success, 2 = warning, 3 = statistic, 4 = error)")
+	nc := fs.Bool("no-color", false, "Disable color output")
+	_ = fs.Parse(args)
+
+	if *nc {
+		color.NoColor = true
+	}
+
+	if err := a.Audit(); err != nil {
+		log.Error(err)
+	}
+}
+
+//
+// cache
+// @Description: Parse the cache command flags and inspect, prune or clear the registry metadata cache
+// @param a *app.Application
+// @param args []string
+func cache(a *app.Application, args []string) {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of juck cache [list|prune|clear]:\n")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&a.NpmCache, "npm-cache", a.NpmCache, "Directory used to cache registry metadata")
+	fs.DurationVar(&a.CacheTTL, "cache-ttl", a.CacheTTL, "Documents older than the given duration are considered stale and get pruned")
+	fs.IntVar(&log.Mode, "log", log.Mode, "Set the log mode (0 = all, 1 = success, 2 = warning, 3 = statistic, 4 = error)")
+	nc := fs.Bool("no-color", false, "Disable color output")
+
+	action := app.CacheList
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") == false {
+		action, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+
+	if *nc {
+		color.NoColor = true
+	}
+
+	if err := a.Cache(action); err != nil {
+		log.Error(err)
+	}
+}
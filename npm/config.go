@@ -0,0 +1,183 @@
+package npm
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var envRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+type Config struct {
+	// Registry is the default registry base url
+	Registry string
+	// PublicRegistry is the registry anyone can publish to. It's used to detect dependency confusion candidates and
+	// never taken from .npmrc files, which usually point to a mirror
+	PublicRegistry string
+	// Scopes contains the registry base url of every configured scope (e.g. @acme => https://npm.acme.com/)
+	Scopes map[string]string
+	// Tokens contains the auth token of every configured registry by its "nerf dart" (e.g. //npm.acme.com/)
+	Tokens map[string]string
+}
+
+//
+// NewConfig
+// @Description: Create a new Config pointing to the public registry
+// @return *Config
+func NewConfig() *Config {
+	return &Config{
+		Registry:       DefaultRegistry,
+		PublicRegistry: DefaultRegistry,
+		Scopes:         map[string]string{},
+		Tokens:         map[string]string{},
+	}
+}
+
+//
+// LoadNpmrc
+// @Description: Load the registry, @scope:registry and _authToken settings of a given .npmrc file
+// @receiver c *Config
+// @param filename string
+// @return error
+func (c *Config) LoadNpmrc(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		value = envRegex.ReplaceAllStringFunc(value, func(s string) string {
+			return os.Getenv(envRegex.FindStringSubmatch(s)[1])
+		})
+
+		switch {
+		case key == "registry":
+			c.SetRegistry(value)
+		case key == "_authToken":
+			c.Tokens[nerfDart(c.Registry)] = value
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			c.SetScopeRegistry(strings.TrimSuffix(key, ":registry"), value)
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+			c.SetToken(strings.TrimSuffix(key, ":_authToken"), value)
+		}
+	}
+	return sc.Err()
+}
+
+//
+// SetRegistry
+// @Description: Set the default registry base url
+// @receiver c *Config
+// @param registry string
+func (c *Config) SetRegistry(registry string) {
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	c.Registry = normalizeRegistry(registry)
+}
+
+//
+// SetPublicRegistry
+// @Description: Set the public registry base url
+// @receiver c *Config
+// @param registry string
+func (c *Config) SetPublicRegistry(registry string) {
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	c.PublicRegistry = normalizeRegistry(registry)
+}
+
+//
+// SetScopeRegistry
+// @Description: Route all packages of a given scope to a registry
+// @receiver c *Config
+// @param scope string
+// @param registry string
+func (c *Config) SetScopeRegistry(scope, registry string) {
+	if strings.HasPrefix(scope, "@") == false {
+		scope = "@" + scope
+	}
+	c.Scopes[scope] = normalizeRegistry(registry)
+}
+
+//
+// SetToken
+// @Description: Set the auth token of a given registry url or nerf dart
+// @receiver c *Config
+// @param registry string
+// @param token string
+func (c *Config) SetToken(registry, token string) {
+	c.Tokens[nerfDart(registry)] = token
+}
+
+//
+// RegistryFor
+// @Description: Get the registry base url responsible for a given package name
+// @receiver c *Config
+// @param name string
+// @return string
+func (c *Config) RegistryFor(name string) string {
+	if strings.HasPrefix(name, "@") {
+		if i := strings.Index(name, "/"); i > 0 {
+			if registry, ok := c.Scopes[name[:i]]; ok {
+				return registry
+			}
+		} else if registry, ok := c.Scopes[name]; ok {
+			return registry
+		}
+	}
+	return c.Registry
+}
+
+//
+// TokenFor
+// @Description: Get the auth token of the most specific registry matching a given url
+// @receiver c *Config
+// @param u string
+// @return string
+func (c *Config) TokenFor(u string) string {
+	target := nerfDart(u)
+	token, length := "", 0
+	for prefix, t := range c.Tokens {
+		if strings.HasPrefix(target, prefix) && len(prefix) > length {
+			token, length = t, len(prefix)
+		}
+	}
+	return token
+}
+
+//
+// nerfDart
+// @Description: Strip the protocol of an url (https://npm.acme.com/path => //npm.acme.com/path/)
+// @param u string
+// @return string
+func nerfDart(u string) string {
+	if i := strings.Index(u, "//"); i >= 0 {
+		u = u[i:]
+	}
+	if strings.HasSuffix(u, "/") == false {
+		u = u + "/"
+	}
+	return u
+}
+
+func normalizeRegistry(registry string) string {
+	if strings.HasSuffix(registry, "/") == false {
+		registry = registry + "/"
+	}
+	return registry
+}
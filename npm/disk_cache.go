@@ -0,0 +1,277 @@
+package npm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultCacheTTL = 24 * time.Hour
+	// tmpPrefix is the prefix of temporary files written before they are renamed into the cache
+	tmpPrefix = ".tmp-"
+)
+
+// entryRegex matches the filenames of cache entries (sha256 hash of url and accept header)
+var entryRegex = regexp.MustCompile(`^[0-9a-f]{64}\.json$`)
+
+// DiskCache is a persistent registry metadata cache keyed by request url (registry + name)
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type CacheEntry struct {
+	Url      string          `json:"url"`
+	Accept   string          `json:"accept,omitempty"`
+	ETag     string          `json:"etag,omitempty"`
+	Fetched  time.Time       `json:"fetched"`
+	NotFound bool            `json:"not_found,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	size     int64
+}
+
+//
+// NewDiskCache
+// @Description: Create a new DiskCache within a given directory
+// @param dir string
+// @param ttl time.Duration entries older than ttl get revalidated
+// @return *DiskCache
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{
+		dir: dir,
+		ttl: ttl,
+	}
+}
+
+//
+// Dir
+// @Description: Get the cache directory
+// @receiver c *DiskCache
+// @return string
+func (c *DiskCache) Dir() string {
+	return c.dir
+}
+
+//
+// Get
+// @Description: Get the cached entry of a given url
+// @receiver c *DiskCache
+// @param u string
+// @param accept string requested document format
+// @return *CacheEntry nil if the url isn't cached
+func (c *DiskCache) Get(u, accept string) *CacheEntry {
+	e, err := c.load(c.filename(u, accept))
+	if err != nil || e.Url != u || e.Accept != accept {
+		return nil
+	}
+	return e
+}
+
+//
+// Put
+// @Description: Store a response (or a not found response if body is nil) of a given url
+// @receiver c *DiskCache
+// @param u string
+// @param accept string requested document format
+// @param etag string
+// @param body []byte
+// @return error
+func (c *DiskCache) Put(u, accept, etag string, body []byte) error {
+	e := &CacheEntry{
+		Url:      u,
+		Accept:   accept,
+		ETag:     etag,
+		Fetched:  time.Now(),
+		NotFound: body == nil,
+	}
+	if body != nil {
+		if json.Valid(body) == false {
+			return nil
+		}
+		e.Body = body
+	}
+	return c.save(e)
+}
+
+//
+// Touch
+// @Description: Mark an entry as revalidated
+// @receiver c *DiskCache
+// @param e *CacheEntry
+// @return error
+func (c *DiskCache) Touch(e *CacheEntry) error {
+	e.Fetched = time.Now()
+	return c.save(e)
+}
+
+//
+// Fresh
+// @Description: Check if an entry is younger than the cache ttl
+// @receiver c *DiskCache
+// @param e *CacheEntry
+// @return bool
+func (c *DiskCache) Fresh(e *CacheEntry) bool {
+	return time.Since(e.Fetched) < c.ttl
+}
+
+//
+// Entries
+// @Description: Get all cached entries sorted by url
+// @receiver c *DiskCache
+// @return []*CacheEntry
+// @return error
+func (c *DiskCache) Entries() ([]*CacheEntry, error) {
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*CacheEntry, 0, len(files))
+	for _, filename := range files {
+		if e, err := c.load(filename); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Url < entries[j].Url
+	})
+	return entries, nil
+}
+
+//
+// Prune
+// @Description: Remove all entries older than the cache ttl and all unreadable files
+// @receiver c *DiskCache
+// @return int number of removed entries
+// @return error
+func (c *DiskCache) Prune() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, filename := range files {
+		if e, err := c.load(filename); err == nil && c.Fresh(e) {
+			continue
+		}
+		if err := os.Remove(filename); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+//
+// Clear
+// @Description: Remove all cached entries and leftover temporary files. Other files and the directory itself are
+// kept - the cache directory may be shared or point to an arbitrary directory
+// @receiver c *DiskCache
+// @return error
+func (c *DiskCache) Clear() error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	tmp, err := filepath.Glob(filepath.Join(c.dir, tmpPrefix+"*"))
+	if err != nil {
+		return err
+	}
+	for _, filename := range append(files, tmp...) {
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// files
+// @Description: Get the filenames of all cache entries
+// @receiver c *DiskCache
+// @return []string
+// @return error
+func (c *DiskCache) files() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(matches))
+	for _, filename := range matches {
+		if entryRegex.MatchString(filepath.Base(filename)) {
+			files = append(files, filename)
+		}
+	}
+	return files, nil
+}
+
+//
+// Size
+// @Description: Get the size of the cache file
+// @receiver e *CacheEntry
+// @return int64
+func (e *CacheEntry) Size() int64 {
+	return e.size
+}
+
+//
+// response
+// @Description: Get the cached response body or the cached not found error
+// @receiver e *CacheEntry
+// @return []byte
+// @return error
+func (e *CacheEntry) response() ([]byte, error) {
+	if e.NotFound {
+		return nil, &NotFoundError{Url: e.Url}
+	}
+	return e.Body, nil
+}
+
+func (c *DiskCache) filename(u, accept string) string {
+	h := sha256.Sum256([]byte(u + "|" + accept))
+	return filepath.Join(c.dir, hex.EncodeToString(h[:])+".json")
+}
+
+func (c *DiskCache) load(filename string) (*CacheEntry, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	e := &CacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	e.size = int64(len(data))
+	return e, nil
+}
+
+func (c *DiskCache) save(e *CacheEntry) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first - parallel runs may share the cache
+	tmp, err := ioutil.TempFile(c.dir, tmpPrefix)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.filename(e.Url, e.Accept))
+}
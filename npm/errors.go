@@ -0,0 +1,71 @@
+package npm
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("npm: not found")
+
+// errNotModified is returned by conditional requests if the cached document is still valid
+var errNotModified = errors.New("npm: not modified")
+
+// InvalidNameError is returned for package names violating the npm naming rules. No request is performed
+type InvalidNameError struct {
+	Name   string
+	Reason string
+}
+
+// NotFoundError is returned if the registry doesn't know the requested resource
+type NotFoundError struct {
+	Url string
+}
+
+// ServerError is returned for any other unsuccessful registry response
+type ServerError struct {
+	Url        string
+	StatusCode int
+	Status     string
+}
+
+// OfflineError is returned in offline mode if a resource isn't cached
+type OfflineError struct {
+	Url string
+}
+
+// IntegrityError is returned if a downloaded tarball doesn't match the integrity or shasum of its version
+type IntegrityError struct {
+	Name      string
+	Version   string
+	Algorithm string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("npm: invalid package name \"%s\": %s", e.Name, e.Reason)
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("npm: not found: %s", e.Url)
+}
+
+//
+// Is
+// @Description: Allow errors.Is(err, ErrNotFound) checks
+// @receiver e *NotFoundError
+// @param target error
+// @return bool
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("npm: invalid response status: %d - %s (%s)", e.StatusCode, e.Status, e.Url)
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("npm: offline mode - not cached: %s", e.Url)
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("npm: %s checksum mismatch of the %s@%s tarball", e.Algorithm, e.Name, e.Version)
+}
@@ -0,0 +1,258 @@
+package npm
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/webklex/juck/utils"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatDot     = "dot"
+	FormatGraphML = "graphml"
+	FormatJson    = "json"
+)
+
+type ExportOptions struct {
+	// Depth limits the exported graph to nodes within the given distance of a root (0 = unlimited)
+	Depth int
+	// Filter is a glob pattern (e.g. @babel/*). Only matching nodes and the nodes pulling them in are exported
+	Filter string
+}
+
+// ExportNode is a graph node as exported
+type ExportNode struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Root is true for packages discovered directly within the source maps
+	Root  bool `json:"root"`
+	Depth int  `json:"depth"`
+}
+
+// ExportGraph is the filtered, exportable representation of a Graph
+type ExportGraph struct {
+	Nodes []*ExportNode `json:"nodes"`
+	Edges []*Edge       `json:"edges"`
+}
+
+//
+// Export
+// @Description: Build the exportable graph applying the depth limit and filter
+// @receiver g *Graph
+// @param opts ExportOptions
+// @return *ExportGraph
+// @return error
+func (g *Graph) Export(opts ExportOptions) (*ExportGraph, error) {
+	if opts.Filter != "" {
+		if _, err := path.Match(opts.Filter, ""); err != nil {
+			return nil, fmt.Errorf("npm: invalid graph filter \"%s\": %s", opts.Filter, err.Error())
+		}
+	}
+
+	// Breadth-first depth of every node
+	depth := map[string]int{}
+	queue := make([]string, 0)
+	for name, version := range g.Roots {
+		k := key(name, version)
+		depth[k] = 0
+		queue = append(queue, k)
+	}
+	adjacent := map[string][]*Edge{}
+	for _, e := range g.Edges {
+		if e.To != "" {
+			adjacent[e.From] = append(adjacent[e.From], e)
+		}
+	}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		for _, e := range adjacent[k] {
+			if _, ok := depth[e.To]; !ok {
+				depth[e.To] = depth[k] + 1
+				queue = append(queue, e.To)
+			}
+		}
+	}
+
+	include := map[string]bool{}
+	for k, d := range depth {
+		include[k] = opts.Depth <= 0 || d <= opts.Depth
+	}
+	if opts.Filter != "" {
+		// Keep matching nodes and all of their ancestors
+		keep := map[string]bool{}
+		queue = queue[:0]
+		for k := range depth {
+			if matched, _ := path.Match(opts.Filter, g.Nodes[k].Name); matched && include[k] {
+				keep[k] = true
+				queue = append(queue, k)
+			}
+		}
+		for len(queue) > 0 {
+			k := queue[0]
+			queue = queue[1:]
+			for _, e := range g.Dependents(k) {
+				if include[e.From] && !keep[e.From] {
+					keep[e.From] = true
+					queue = append(queue, e.From)
+				}
+			}
+		}
+		include = keep
+	}
+
+	eg := &ExportGraph{
+		Nodes: make([]*ExportNode, 0),
+		Edges: make([]*Edge, 0),
+	}
+	for k, ok := range include {
+		if !ok {
+			continue
+		}
+		rp := g.Nodes[k]
+		eg.Nodes = append(eg.Nodes, &ExportNode{
+			Key:     k,
+			Name:    rp.Name,
+			Version: rp.Version,
+			Root:    depth[k] == 0,
+			Depth:   depth[k],
+		})
+	}
+	sort.Slice(eg.Nodes, func(i, j int) bool {
+		return eg.Nodes[i].Key < eg.Nodes[j].Key
+	})
+	for _, e := range g.Edges {
+		if e.To != "" && include[e.From] && include[e.To] {
+			eg.Edges = append(eg.Edges, e)
+		}
+	}
+	return eg, nil
+}
+
+//
+// Write
+// @Description: Write the graph in a given format (dot, graphml or json)
+// @receiver eg *ExportGraph
+// @param w io.Writer
+// @param format string
+// @return error
+func (eg *ExportGraph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatDot:
+		return eg.writeDot(w)
+	case FormatGraphML:
+		return eg.writeGraphML(w)
+	case FormatJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(eg)
+	}
+	return fmt.Errorf("npm: unknown graph format \"%s\"", format)
+}
+
+func (eg *ExportGraph) writeDot(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("\trankdir=LR;\n\tnode [shape=box, fontname=\"Helvetica\"];\n")
+	for _, n := range eg.Nodes {
+		style := ""
+		if n.Root {
+			style = ", style=filled, fillcolor=\"#9ecae1\""
+		}
+		b.WriteString(fmt.Sprintf("\t%s [label=%s%s];\n", utils.DotQuote(n.Key), utils.DotQuote(n.Key), style))
+	}
+	for _, e := range eg.Edges {
+		label := e.Range
+		if e.Kind != "" && e.Kind != KindProd {
+			label = e.Kind + " " + label
+		}
+		b.WriteString(fmt.Sprintf("\t%s -> %s [label=%s];\n", utils.DotQuote(e.From), utils.DotQuote(e.To), utils.DotQuote(label)))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (eg *ExportGraph) writeGraphML(w io.Writer) error {
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type keyDef struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+	doc := struct {
+		XMLName xml.Name `xml:"graphml"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Keys    []keyDef `xml:"key"`
+		Graph   struct {
+			ID          string `xml:"id,attr"`
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []node `xml:"node"`
+			Edges       []edge `xml:"edge"`
+		} `xml:"graph"`
+	}{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []keyDef{
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "version", For: "node", AttrName: "version", AttrType: "string"},
+			{ID: "root", For: "node", AttrName: "root", AttrType: "boolean"},
+			{ID: "depth", For: "node", AttrName: "depth", AttrType: "int"},
+			{ID: "range", For: "edge", AttrName: "range", AttrType: "string"},
+			{ID: "kind", For: "edge", AttrName: "kind", AttrType: "string"},
+			{ID: "alias", For: "edge", AttrName: "alias", AttrType: "string"},
+		},
+	}
+	doc.Graph.ID = "dependencies"
+	doc.Graph.EdgeDefault = "directed"
+	for _, n := range eg.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node{
+			ID: n.Key,
+			Data: []data{
+				{Key: "name", Value: n.Name},
+				{Key: "version", Value: n.Version},
+				{Key: "root", Value: fmt.Sprintf("%t", n.Root)},
+				{Key: "depth", Value: fmt.Sprintf("%d", n.Depth)},
+			},
+		})
+	}
+	for _, e := range eg.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			Source: e.From,
+			Target: e.To,
+			Data: []data{
+				{Key: "range", Value: e.Range},
+				{Key: "kind", Value: e.Kind},
+				{Key: "alias", Value: e.Name},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,68 @@
+package npm
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// nameRegex contains all characters left untouched by encodeURIComponent
+	nameRegex      = regexp.MustCompile(`^[A-Za-z0-9\-_.!~*'()]+$`)
+	blacklistNames = []string{"node_modules", "favicon.ico"}
+)
+
+//
+// ValidateName
+// @Description: Validate a package name against the npm naming rules. Rules which only apply to new packages
+// (e.g. lowercase names) are ignored, since older packages may still be published with them
+// @param name string
+// @return error *InvalidNameError
+func ValidateName(name string) error {
+	invalid := func(reason string) error {
+		return &InvalidNameError{Name: name, Reason: reason}
+	}
+	if name == "" {
+		return invalid("name length must be greater than zero")
+	}
+	if strings.TrimSpace(name) != name {
+		return invalid("name cannot contain leading or trailing spaces")
+	}
+	if strings.HasPrefix(name, ".") {
+		return invalid("name cannot start with a period")
+	}
+	if strings.HasPrefix(name, "_") {
+		return invalid("name cannot start with an underscore")
+	}
+	for _, b := range blacklistNames {
+		if strings.ToLower(name) == b {
+			return invalid(b + " is a blacklisted name")
+		}
+	}
+
+	parts := []string{name}
+	if strings.HasPrefix(name, "@") {
+		parts = strings.Split(name[1:], "/")
+		if len(parts) != 2 {
+			return invalid("scoped names must have the format @scope/name")
+		}
+		if strings.HasPrefix(parts[1], ".") || strings.HasPrefix(parts[1], "_") {
+			return invalid("name cannot start with a period or an underscore")
+		}
+	}
+	for _, p := range parts {
+		if nameRegex.MatchString(p) == false {
+			return invalid("name can only contain URL-friendly characters")
+		}
+	}
+	return nil
+}
+
+//
+// EscapeName
+// @Description: Escape a package name for registry urls. The scope "@" is kept and the "/" encoded
+// (e.g. @babel/core => @babel%2Fcore)
+// @param name string
+// @return string
+func EscapeName(name string) string {
+	return strings.Replace(name, "/", "%2F", 1)
+}
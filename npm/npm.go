@@ -2,17 +2,32 @@ package npm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/webklex/juck/utils"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultRegistry = "https://registry.npmjs.org/"
+
+	// AcceptAbbreviated requests the abbreviated install metadata, falling back to the full document
+	AcceptAbbreviated = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8, */*"
+	AcceptFull        = "application/json"
 )
 
 type Npm struct {
-	registry string
-	frontend string
+	config       *Config
+	frontend     string
+	tarballCache string
+	diskCache    *DiskCache
+	offline      bool
+	workers      int
+	kinds        []string
 }
 
 type cacheItem struct {
@@ -20,75 +35,316 @@ type cacheItem struct {
 	response *RepositoryResponse
 }
 
-var cache = map[string]*cacheItem{}
+// DefaultWorkers is the default number of concurrent registry requests
+const DefaultWorkers = 8
+
+var (
+	cache      = map[string]*cacheItem{}
+	scopes     = map[string]bool{}
+	cacheMutex = sync.RWMutex{}
+	flights    = &flightGroup{}
+)
 
 func NewNpmRegistry() *Npm {
 	return &Npm{
-		registry: "https://registry.npmjs.org/",
+		config:  NewConfig(),
+		workers: DefaultWorkers,
+		kinds:   DefaultKinds,
 	}
 }
 
+//
+// Config
+// @Description: Get the registry configuration
+// @receiver npm *Npm
+// @return *Config
+func (npm *Npm) Config() *Config {
+	return npm.config
+}
+
+//
+// SetRegistry
+// @Description: Set the default registry base url (e.g. a local mirror)
+// @receiver npm *Npm
+// @param registry string
+func (npm *Npm) SetRegistry(registry string) {
+	npm.config.SetRegistry(registry)
+}
+
+//
+// SetDiskCache
+// @Description: Persist registry metadata within a given cache. Nil disables the cache
+// @receiver npm *Npm
+// @param c *DiskCache
+func (npm *Npm) SetDiskCache(c *DiskCache) {
+	npm.diskCache = c
+}
+
+//
+// SetOffline
+// @Description: Only serve registry metadata and tarballs from the caches - no requests are performed
+// @receiver npm *Npm
+// @param offline bool
+func (npm *Npm) SetOffline(offline bool) {
+	npm.offline = offline
+}
+
+//
+// SetWorkers
+// @Description: Set the maximum number of concurrent registry requests used to resolve dependencies
+// @receiver npm *Npm
+// @param workers int
+func (npm *Npm) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	npm.workers = workers
+}
+
+//
+// SetDependencyKinds
+// @Description: Set the dependency kinds (prod, peer, peer-optional, optional, dev) followed while resolving the graph
+// @receiver npm *Npm
+// @param kinds []string
+// @return error
+func (npm *Npm) SetDependencyKinds(kinds []string) error {
+	for _, k := range kinds {
+		if k != KindProd && k != KindPeer && k != KindPeerOptional && k != KindOptional && k != KindDev {
+			return fmt.Errorf("npm: unknown dependency kind \"%s\"", k)
+		}
+	}
+	npm.kinds = kinds
+	return nil
+}
+
+//
+// SetTarballCache
+// @Description: Set the directory used to cache downloaded tarballs. An empty string disables the cache
+// @receiver npm *Npm
+// @param dir string
+func (npm *Npm) SetTarballCache(dir string) {
+	npm.tarballCache = dir
+}
+
+//
+// Get
+// @Description: Get the abbreviated metadata document of a package. It only contains the data required to
+// install a package (versions, dependencies, dist, ..) - use GetFull for license, maintainer or repository data
+// @receiver npm *Npm
+// @param name string
+// @return *RepositoryResponse
+// @return error
 func (npm *Npm) Get(name string) (*RepositoryResponse, error) {
-	if c, ok := cache[name]; ok {
-		return c.response, c.error
+	// The full document is a superset of the abbreviated one
+	if c, ok := cached(npm.cacheKey(npm.config.RegistryFor(name), name, AcceptFull)); ok && c.error == nil {
+		return c.response, nil
 	}
-	u, err := url.Parse(npm.registry + url.QueryEscape(name))
-	if err != nil {
-		return registerCache(name, nil, err)
+	return npm.get(npm.config.RegistryFor(name), name, AcceptAbbreviated)
+}
+
+//
+// GetPublic
+// @Description: Get the abbreviated metadata document of a package from the public registry - the configured
+// default and scope registries (e.g. mirrors) are ignored. Used to check if a (private) package name has been claimed
+// publicly
+// @receiver npm *Npm
+// @param name string
+// @return *RepositoryResponse
+// @return error
+func (npm *Npm) GetPublic(name string) (*RepositoryResponse, error) {
+	if c, ok := cached(npm.cacheKey(npm.config.PublicRegistry, name, AcceptFull)); ok && c.error == nil {
+		return c.response, nil
 	}
-	resp, err := npm.request(http.MethodGet, u, nil)
-	if err != nil {
-		return registerCache(name, nil, err)
+	return npm.get(npm.config.PublicRegistry, name, AcceptAbbreviated)
+}
+
+//
+// GetFull
+// @Description: Get the full metadata document of a package including readme, license, maintainer and
+// repository data
+// @receiver npm *Npm
+// @param name string
+// @return *RepositoryResponse
+// @return error
+func (npm *Npm) GetFull(name string) (*RepositoryResponse, error) {
+	return npm.get(npm.config.RegistryFor(name), name, AcceptFull)
+}
+
+func (npm *Npm) get(registry, name, accept string) (*RepositoryResponse, error) {
+	key := npm.cacheKey(registry, name, accept)
+	if c, ok := cached(key); ok {
+		return c.response, c.error
 	}
+	v, err := flights.Do(key, func() (interface{}, error) {
+		if err := ValidateName(name); err != nil {
+			return registerCache(key, nil, err)
+		}
+		u, err := url.Parse(registry + EscapeName(name))
+		if err != nil {
+			return registerCache(key, nil, err)
+		}
+		resp, err := npm.fetch(u, accept)
+		if err != nil {
+			return registerCache(key, nil, err)
+		}
 
-	var r RepositoryResponse
-	return registerCache(name, &r, json.Unmarshal(resp, &r))
+		var r RepositoryResponse
+		return registerCache(key, &r, json.Unmarshal(resp, &r))
+	})
+	r, _ := v.(*RepositoryResponse)
+	return r, err
 }
 
-func (npm *Npm) Dependencies(name string, dependencies ...string) (result []string, err error) {
-	pkg, err := npm.Get(name)
-	if err != nil {
-		return nil, err
+func (npm *Npm) cacheKey(registry, name, accept string) string {
+	return registry + name + "|" + accept
+}
+
+//
+// ScopeExists
+// @Description: Check if a scope (user or organization) has been claimed on the public registry. The configured
+// registries are ignored - a scope only claimed privately can still be registered publicly and mirrors usually don't
+// serve the scope endpoints at all
+// @receiver npm *Npm
+// @param scope string
+// @return bool
+// @return error
+func (npm *Npm) ScopeExists(scope string) (bool, error) {
+	scope = strings.TrimPrefix(scope, "@")
+	registry := npm.config.PublicRegistry
+	key := registry + scope
+	cacheMutex.RLock()
+	exists, ok := scopes[key]
+	cacheMutex.RUnlock()
+	if ok {
+		return exists, nil
+	}
+	if err := ValidateName("@" + scope + "/package"); err != nil {
+		return false, err
 	}
 
-	result = append(dependencies, pkg.Name())
-	for dependency, _ := range pkg.Dependencies() {
-		if utils.InStringList(result, dependency) {
-			continue
+	v, err := flights.Do("scope|"+key, func() (interface{}, error) {
+		// Scopes are owned by either an organization or a user - npm uses the same endpoint pair
+		exists := false
+		for _, owner := range []string{"org", "user"} {
+			u, err := url.Parse(registry + "-/" + owner + "/" + url.PathEscape(scope) + "/package")
+			if err != nil {
+				return false, err
+			}
+			if _, err = npm.fetch(u, AcceptFull); err == nil {
+				exists = true
+				break
+			} else if !errors.Is(err, ErrNotFound) {
+				return false, err
+			}
 		}
-		if entries, err2 := npm.Dependencies(dependency, result...); entries != nil {
-			result = entries
-		} else if err2 != nil {
-			result = append(result, dependency)
+		cacheMutex.Lock()
+		scopes[key] = exists
+		cacheMutex.Unlock()
+		return exists, nil
+	})
+	exists, _ = v.(bool)
+	return exists, err
+}
+
+//
+// fetch
+// @Description: Get a registry metadata document in a given format. Fresh documents are served from the disk
+// cache, stale ones get revalidated by their ETag
+// @receiver npm *Npm
+// @param u *url.URL
+// @param accept string
+// @return []byte
+// @return error
+func (npm *Npm) fetch(u *url.URL, accept string) ([]byte, error) {
+	header := http.Header{}
+	header.Set("Accept", accept)
+	if npm.diskCache == nil {
+		if npm.offline {
+			return nil, &OfflineError{Url: u.String()}
 		}
+		data, _, err := npm.requestHeader(http.MethodGet, u, nil, header)
+		return data, err
+	}
+
+	entry := npm.diskCache.Get(u.String(), accept)
+	if entry != nil && (npm.offline || npm.diskCache.Fresh(entry)) {
+		return entry.response()
+	} else if npm.offline {
+		return nil, &OfflineError{Url: u.String()}
 	}
 
-	return
+	if entry != nil && entry.ETag != "" {
+		header.Set("If-None-Match", entry.ETag)
+	}
+	data, resHeader, err := npm.requestHeader(http.MethodGet, u, nil, header)
+	var notFound *NotFoundError
+	switch {
+	case err == errNotModified && entry != nil:
+		_ = npm.diskCache.Touch(entry)
+		return entry.response()
+	case errors.As(err, &notFound):
+		_ = npm.diskCache.Put(u.String(), accept, "", nil)
+		return nil, err
+	case err != nil:
+		return nil, err
+	}
+	_ = npm.diskCache.Put(u.String(), accept, resHeader.Get("ETag"), data)
+	return data, nil
 }
 
 func (npm *Npm) request(method string, u *url.URL, body io.Reader) ([]byte, error) {
+	data, _, err := npm.requestHeader(method, u, body, nil)
+	return data, err
+}
+
+func (npm *Npm) requestHeader(method string, u *url.URL, body io.Reader, header http.Header) ([]byte, http.Header, error) {
 	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
-		return nil, fmt.Errorf("npm: could not create request: %s\n", err)
+		return nil, nil, fmt.Errorf("npm: could not create request: %s\n", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if token := npm.config.TokenFor(u.String()); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("npm: error making http request: %s\n", err)
+		return nil, nil, fmt.Errorf("npm: error making http request: %s\n", err)
 	}
+	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotModified {
+		return nil, res.Header, errNotModified
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, res.Header, &NotFoundError{Url: u.String()}
+	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, fmt.Errorf("npm: invalid response status: %d - %s\n", res.StatusCode, res.Status)
+		return nil, res.Header, &ServerError{Url: u.String(), StatusCode: res.StatusCode, Status: res.Status}
 	}
 
 	resBody, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("npm: could not read response body: %s\n", err)
+		return nil, nil, fmt.Errorf("npm: could not read response body: %s\n", err)
 	}
-	return resBody, nil
+	return resBody, res.Header, nil
+}
+
+func cached(key string) (*cacheItem, bool) {
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+	c, ok := cache[key]
+	return c, ok
 }
 
 func registerCache(name string, r *RepositoryResponse, err error) (*RepositoryResponse, error) {
+	if err != nil {
+		r = nil
+	}
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
 	cache[name] = &cacheItem{
 		error:    err,
 		response: r,
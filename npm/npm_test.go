@@ -0,0 +1,323 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//
+// newTestRegistry
+// @Description: Start a fake registry serving the given paths (everything else is a 404)
+// @param t *testing.T
+// @param documents map[string]string path => body
+// @return *httptest.Server
+func newTestRegistry(t *testing.T, documents map[string]string) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := documents[r.URL.EscapedPath()]
+		if !ok {
+			body, ok = documents[r.URL.Path]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestScopeExists(t *testing.T) {
+	s := newTestRegistry(t, map[string]string{
+		"/-/org/acme/package":      `{}`,
+		"/-/user/someuser/package": `{}`,
+	})
+	n := NewNpmRegistry()
+	n.Config().SetPublicRegistry(s.URL)
+
+	for scope, expected := range map[string]bool{
+		"@acme":     true,
+		"@someuser": true,
+		"@nobody":   false,
+	} {
+		exists, err := n.ScopeExists(scope)
+		if err != nil {
+			t.Fatalf("%s: %s", scope, err)
+		}
+		if exists != expected {
+			t.Errorf("%s: expected %v, got %v", scope, expected, exists)
+		}
+	}
+}
+
+func TestScopeRegistryIgnoredByPublicLookups(t *testing.T) {
+	public := newTestRegistry(t, map[string]string{})
+	private := newTestRegistry(t, map[string]string{
+		"/@private%2Flib":        `{"name":"@private/lib"}`,
+		"/-/org/private/package": `{}`,
+	})
+	n := NewNpmRegistry()
+	n.SetRegistry(public.URL)
+	n.Config().SetPublicRegistry(public.URL)
+	n.Config().SetScopeRegistry("@private", private.URL)
+
+	if _, err := n.Get("@private/lib"); err != nil {
+		t.Fatalf("expected the package on the scope registry: %s", err)
+	}
+	if _, err := n.GetPublic("@private/lib"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the package to be unknown to the default registry, got %v", err)
+	}
+	if exists, err := n.ScopeExists("@private"); err != nil || exists {
+		t.Errorf("expected the scope to be unclaimed on the default registry, got %v (%v)", exists, err)
+	}
+}
+
+func TestMirrorIgnoredByPublicLookups(t *testing.T) {
+	public := newTestRegistry(t, map[string]string{
+		"/-/org/babel/package": `{}`,
+	})
+	// Mirrors serve private packages next to the public ones, but no scope endpoints
+	mirror := newTestRegistry(t, map[string]string{
+		"/internal-lib": `{"name":"internal-lib"}`,
+	})
+	n := NewNpmRegistry()
+	n.SetRegistry(mirror.URL)
+	n.Config().SetPublicRegistry(public.URL)
+
+	if _, err := n.Get("internal-lib"); err != nil {
+		t.Fatalf("expected the package on the mirror: %s", err)
+	}
+	if _, err := n.GetPublic("internal-lib"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the package to be unknown to the public registry, got %v", err)
+	}
+	if exists, err := n.ScopeExists("@babel"); err != nil || !exists {
+		t.Errorf("expected the scope to be claimed on the public registry, got %v (%v)", exists, err)
+	}
+}
+
+//
+// testTarball
+// @Description: Create a gzipped tarball containing a single file flagged with a given type
+// @param t *testing.T
+// @param typeflag byte
+// @return []byte
+func testTarball(t *testing.T, typeflag byte) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	content := []byte("module.exports = 1;")
+	if err := tw.WriteHeader(&tar.Header{Name: "package/index.js", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The writer doesn't emit TypeRegA anymore - patch the flag and the header checksum
+	data := buf.Bytes()
+	data[156] = typeflag
+	copy(data[148:156], "        ")
+	sum := 0
+	for _, b := range data[:512] {
+		sum += int(b)
+	}
+	copy(data[148:156], fmt.Sprintf("%06o\x00 ", sum))
+
+	gz := &bytes.Buffer{}
+	zw := gzip.NewWriter(gz)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	return gz.Bytes()
+}
+
+func TestTarballHashesRegA(t *testing.T) {
+	s := newTestRegistry(t, map[string]string{
+		"/a/-/a-1.0.0.tgz": string(testTarball(t, '\x00')),
+	})
+	n := NewNpmRegistry()
+	v := &Version{Name: "a", Version: "1.0.0"}
+	v.Dist.Tarball = s.URL + "/a/-/a-1.0.0.tgz"
+	hashes, err := n.TarballHashes(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hashes["index.js"]; !ok {
+		t.Errorf("expected the TypeRegA file to be hashed, got %v", hashes)
+	}
+}
+
+func TestTarballCachePath(t *testing.T) {
+	dir := t.TempDir()
+	n := NewNpmRegistry()
+	n.SetTarballCache(filepath.Join(dir, "cache"))
+	for _, v := range []*Version{
+		{Name: "../../evil", Version: "1.0.0"},
+		{Name: "@scope/..", Version: "1.0.0"},
+		{Name: "a", Version: "../../../evil"},
+		{Name: "a", Version: "1.0.0/../../x"},
+	} {
+		v.Dist.Tarball = "http://127.0.0.1:1/a.tgz"
+		if _, err := n.tarball(v); err == nil || strings.Contains(err.Error(), "connect") {
+			t.Errorf("%s@%s: expected an invalid name or version, got %v", v.Name, v.Version, err)
+		}
+	}
+	if _, err := n.tarballFilename(&Version{Name: "@scope/a", Version: "1.0.0-beta.1"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTarballIntegrity(t *testing.T) {
+	data := testTarball(t, tar.TypeReg)
+	s := newTestRegistry(t, map[string]string{
+		"/a/-/a-1.0.0.tgz": string(data),
+	})
+	sha512sum := sha512.Sum512(data)
+	sha1sum := sha1.Sum(data)
+	integrity := "sha512-" + base64.StdEncoding.EncodeToString(sha512sum[:])
+	tampered := "sha512-" + base64.StdEncoding.EncodeToString(make([]byte, sha512.Size))
+
+	tests := []struct {
+		name      string
+		integrity string
+		shasum    string
+		valid     bool
+	}{
+		{"integrity", integrity, "", true},
+		{"integrity with a weaker hash", "sha1-" + base64.StdEncoding.EncodeToString(sha1sum[:]) + " " + integrity, "", true},
+		{"tampered integrity", tampered, hex.EncodeToString(sha1sum[:]), false},
+		{"shasum", "", hex.EncodeToString(sha1sum[:]), true},
+		{"tampered shasum", "", strings.Repeat("0", 40), false},
+		{"unsupported integrity", "md5-AAAA", hex.EncodeToString(sha1sum[:]), true},
+		{"no checksum", "", "", true},
+	}
+	for _, tt := range tests {
+		cache := t.TempDir()
+		n := NewNpmRegistry()
+		n.SetTarballCache(cache)
+		v := &Version{Name: "a", Version: "1.0.0"}
+		v.Dist.Tarball = s.URL + "/a/-/a-1.0.0.tgz"
+		v.Dist.Integrity, v.Dist.Shasum = tt.integrity, tt.shasum
+
+		_, err := n.tarball(v)
+		var integrityErr *IntegrityError
+		if tt.valid && err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+		} else if !tt.valid && !errors.As(err, &integrityErr) {
+			t.Errorf("%s: expected an integrity error, got %v", tt.name, err)
+		}
+		_, err = os.Stat(filepath.Join(cache, "a", "1.0.0.tgz"))
+		if cached := err == nil; cached != tt.valid {
+			t.Errorf("%s: expected the tarball to be cached: %v, got %v", tt.name, tt.valid, cached)
+		}
+	}
+}
+
+func TestTarballCorruptedCache(t *testing.T) {
+	data := testTarball(t, tar.TypeReg)
+	s := newTestRegistry(t, map[string]string{
+		"/a/-/a-1.0.0.tgz": string(data),
+	})
+	cache := t.TempDir()
+	filename := filepath.Join(cache, "a", "1.0.0.tgz")
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filename, []byte("corrupted"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	n := NewNpmRegistry()
+	n.SetTarballCache(cache)
+	sum := sha1.Sum(data)
+	v := &Version{Name: "a", Version: "1.0.0"}
+	v.Dist.Tarball = s.URL + "/a/-/a-1.0.0.tgz"
+	v.Dist.Shasum = hex.EncodeToString(sum[:])
+
+	result, err := n.tarball(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, data) {
+		t.Error("expected the corrupted cache entry to be downloaded again")
+	}
+	if cached, _ := ioutil.ReadFile(filename); !bytes.Equal(cached, data) {
+		t.Error("expected the corrupted cache entry to be replaced")
+	}
+}
+
+func TestRequirementsOptionalPeers(t *testing.T) {
+	v := &Version{}
+	if err := json.Unmarshal([]byte(`{
+		"dependencies": {"a": "^1.0.0"},
+		"peerDependencies": {"react": "^18.0.0", "typescript": ">=4"},
+		"peerDependenciesMeta": {"typescript": {"optional": true}}
+	}`), v); err != nil {
+		t.Fatal(err)
+	}
+	kinds := func(requirements []*Requirement) map[string]string {
+		result := map[string]string{}
+		for _, r := range requirements {
+			result[r.Name] = r.Kind
+		}
+		return result
+	}
+
+	got := kinds(v.Requirements(DefaultKinds...))
+	if _, ok := got["typescript"]; ok || got["react"] != KindPeer || got["a"] != KindProd {
+		t.Errorf("optional peers must not be followed by default: %v", got)
+	}
+	got = kinds(v.Requirements(KindProd, KindPeer, KindPeerOptional))
+	if got["typescript"] != KindPeerOptional || got["react"] != KindPeer {
+		t.Errorf("expected the optional peer to be tagged %s: %v", KindPeerOptional, got)
+	}
+}
+
+func TestDiskCacheClearKeepsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := NewDiskCache(dir, DefaultCacheTTL)
+	if err := c.Put("https://registry.npmjs.org/lodash", AcceptAbbreviated, "", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	foreign := []string{"package.json", "notes.txt", filepath.Join("sub", "a.json")}
+	for _, f := range foreign {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, f), []byte(`{}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, tmpPrefix+"123"), []byte(`{`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := c.Entries(); len(entries) != 0 {
+		t.Errorf("expected no cache entries, got %d", len(entries))
+	}
+	if _, err := os.Stat(filepath.Join(dir, tmpPrefix+"123")); !os.IsNotExist(err) {
+		t.Error("expected temporary files to be removed")
+	}
+	for _, f := range foreign {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %s to be kept: %s", f, err.Error())
+		}
+	}
+}
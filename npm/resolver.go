@@ -0,0 +1,245 @@
+package npm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Graph is the resolved dependency graph. Nodes are identified by their "name@version" key
+type Graph struct {
+	// Roots contains the resolved version of every root package
+	Roots map[string]string           `json:"roots"`
+	Nodes map[string]*ResolvedPackage `json:"nodes"`
+	Edges []*Edge                     `json:"edges"`
+}
+
+type Edge struct {
+	From string `json:"from"`
+	// To is empty if the dependency couldn't be resolved
+	To string `json:"to,omitempty"`
+	// Name is the dependency name as requested by the dependent package (may be an alias)
+	Name  string `json:"name"`
+	Range string `json:"range"`
+	Kind  string `json:"kind"`
+}
+
+type Lockfile struct {
+	// Roots contains the resolved version of every root package
+	Roots    map[string]string           `json:"roots"`
+	Packages map[string]*ResolvedPackage `json:"packages"`
+}
+
+type ResolvedPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Resolved  string `json:"resolved,omitempty"`
+	Integrity string `json:"integrity,omitempty"`
+	// Requires contains the requested range of every dependency
+	Requires map[string]string `json:"requires,omitempty"`
+	// Kinds contains the kind (prod, peer, optional or dev) of every dependency
+	Kinds map[string]string `json:"kinds,omitempty"`
+	// Dependencies contains the resolved version of every dependency
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// resolution is the result of resolving a single dependency edge
+type resolution struct {
+	from    *ResolvedPackage
+	name    string
+	spec    string
+	kind    string
+	pkg     *RepositoryResponse
+	version string
+	err     error
+}
+
+//
+// Resolve
+// @Description: Resolve the dependency graph of the given root packages breadth-first. Every dependency edge is
+// resolved to the highest published version satisfying its range. Roots without a known version use the latest
+// version. Each level of the graph is resolved concurrently by a bounded number of workers
+// @receiver npm *Npm
+// @param roots map[string]string name => version
+// @return *Graph
+func (npm *Npm) Resolve(roots map[string]string) *Graph {
+	g := &Graph{
+		Roots: map[string]string{},
+		Nodes: map[string]*ResolvedPackage{},
+		Edges: make([]*Edge, 0),
+	}
+
+	names := make([]string, 0, len(roots))
+	for name := range roots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	packages := make([]*RepositoryResponse, len(names))
+	parallel(len(names), npm.workers, func(i int) {
+		packages[i], _ = npm.Get(names[i])
+	})
+
+	frontier := make([]*ResolvedPackage, 0)
+	for i, name := range names {
+		if packages[i] == nil {
+			continue
+		}
+		version := roots[name]
+		if _, ok := packages[i].RepositoryVersions[version]; !ok {
+			version = packages[i].DistTags[TagLatest]
+		}
+		if rp := g.add(packages[i], version, npm.kinds); rp != nil {
+			g.Roots[name] = version
+			frontier = append(frontier, rp)
+		}
+	}
+
+	for len(frontier) > 0 {
+		jobs := make([]*resolution, 0)
+		for _, rp := range frontier {
+			for _, dependency := range sortedKeys(rp.Requires) {
+				jobs = append(jobs, &resolution{from: rp, name: dependency, spec: rp.Requires[dependency], kind: rp.Kinds[dependency]})
+			}
+		}
+		parallel(len(jobs), npm.workers, func(i int) {
+			jobs[i].pkg, jobs[i].version, jobs[i].err = npm.resolveSpec(jobs[i].name, jobs[i].spec)
+		})
+
+		frontier = make([]*ResolvedPackage, 0)
+		for _, job := range jobs {
+			edge := &Edge{
+				From:  key(job.from.Name, job.from.Version),
+				Name:  job.name,
+				Range: job.spec,
+				Kind:  job.kind,
+			}
+			g.Edges = append(g.Edges, edge)
+			if job.err != nil {
+				continue
+			}
+			edge.To = key(job.pkg.Name(), job.version)
+			job.from.Dependencies[job.name] = job.version
+			if _, ok := g.Nodes[edge.To]; ok {
+				continue
+			}
+			if next := g.add(job.pkg, job.version, npm.kinds); next != nil {
+				frontier = append(frontier, next)
+			}
+		}
+	}
+
+	return g
+}
+
+//
+// Lockfile
+// @Description: Get the lockfile representation of the graph
+// @receiver g *Graph
+// @return *Lockfile
+func (g *Graph) Lockfile() *Lockfile {
+	return &Lockfile{
+		Roots:    g.Roots,
+		Packages: g.Nodes,
+	}
+}
+
+//
+// Keys
+// @Description: Get all resolved packages as sorted "name@version" list
+// @receiver g *Graph
+// @return []string
+func (g *Graph) Keys() (keys []string) {
+	for k := range g.Nodes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
+
+//
+// Dependents
+// @Description: Get all edges pointing to a given node
+// @receiver g *Graph
+// @param k string "name@version"
+// @return []*Edge
+func (g *Graph) Dependents(k string) (edges []*Edge) {
+	for _, e := range g.Edges {
+		if e.To == k {
+			edges = append(edges, e)
+		}
+	}
+	return
+}
+
+//
+// add
+// @Description: Add a resolved package version to the graph
+// @receiver g *Graph
+// @param pkg *RepositoryResponse
+// @param version string
+// @param kinds []string dependency kinds to follow
+// @return *ResolvedPackage nil if the version is unknown
+func (g *Graph) add(pkg *RepositoryResponse, version string, kinds []string) *ResolvedPackage {
+	v, ok := pkg.RepositoryVersions[version]
+	if !ok {
+		return nil
+	}
+	rp := &ResolvedPackage{
+		Name:         pkg.Name(),
+		Version:      version,
+		Resolved:     v.Dist.Tarball,
+		Integrity:    v.Dist.Integrity,
+		Requires:     map[string]string{},
+		Kinds:        map[string]string{},
+		Dependencies: map[string]string{},
+	}
+	for _, r := range v.Requirements(kinds...) {
+		rp.Requires[r.Name] = r.Range
+		rp.Kinds[r.Name] = r.Kind
+	}
+	g.Nodes[key(rp.Name, version)] = rp
+	return rp
+}
+
+//
+// resolveSpec
+// @Description: Resolve a dependency specification (range, dist-tag or npm alias) to a published version
+// @receiver npm *Npm
+// @param name string
+// @param spec string
+// @return *RepositoryResponse the real package
+// @return string the resolved version
+// @return error
+func (npm *Npm) resolveSpec(name, spec string) (*RepositoryResponse, string, error) {
+	if strings.HasPrefix(spec, "npm:") {
+		// npm:real-name@^1.2.3
+		alias := strings.TrimPrefix(spec, "npm:")
+		spec = ""
+		if i := strings.LastIndex(alias, "@"); i > 0 {
+			alias, spec = alias[:i], alias[i+1:]
+		}
+		name = alias
+	}
+	pkg, err := npm.Get(name)
+	if err != nil {
+		return nil, "", err
+	}
+	version, err := pkg.Resolve(spec)
+	if err != nil {
+		return nil, "", err
+	}
+	return pkg, version, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func key(name, version string) string {
+	return fmt.Sprintf("%s@%s", name, version)
+}
@@ -3,32 +3,54 @@ package npm
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"github.com/webklex/juck/semver"
+	"sort"
+	"strings"
 	"time"
 )
 
+const (
+	TagLatest = "latest"
+
+	KindProd     = "prod"
+	KindDev      = "dev"
+	KindPeer     = "peer"
+	KindOptional = "optional"
+	// KindPeerOptional peers are marked optional by peerDependenciesMeta - npm doesn't install them
+	KindPeerOptional = "peer-optional"
+
+	timeCreated     = "created"
+	timeModified    = "modified"
+	timeUnpublished = "unpublished"
+)
+
+// DefaultKinds are the dependency kinds installed by npm by default
+var DefaultKinds = []string{KindProd, KindPeer, KindOptional}
+
 type RepositoryResponse struct {
-	ID                    string `json:"_id"`
-	Rev                   string `json:"_rev"`
-	RepositoryName        string `json:"name"`
-	RepositoryDescription string `json:"description"`
-	DistTags              struct {
-		Latest string `json:"latest"`
-	} `json:"dist-tags"`
-	RepositoryVersions map[string]Version   `json:"versions"`
-	Readme             string               `json:"readme"`
-	Maintainers        []User               `json:"maintainers"`
-	Time               map[string]time.Time `json:"time"`
-	Homepage           string               `json:"homepage"`
-	Keywords           StringList           `json:"keywords"`
-	Repository         Repository           `json:"repository"`
-	RepositoryAuthor   User                 `json:"author"`
-	Bugs               struct {
+	ID                    string             `json:"_id"`
+	Rev                   string             `json:"_rev"`
+	RepositoryName        string             `json:"name"`
+	RepositoryDescription string             `json:"description"`
+	DistTags              map[string]string  `json:"dist-tags"`
+	RepositoryVersions    map[string]Version `json:"versions"`
+	Readme                string             `json:"readme"`
+	Maintainers           []User             `json:"maintainers"`
+	Time                  TimeMap            `json:"time"`
+	Homepage              string             `json:"homepage"`
+	Keywords              StringList         `json:"keywords"`
+	Repository            Repository         `json:"repository"`
+	RepositoryAuthor      User               `json:"author"`
+	Bugs                  struct {
 		Url string `json:"url"`
 	} `json:"bugs"`
-	RepositoryLicense string          `json:"license"`
+	RepositoryLicense License         `json:"license"`
 	ReadmeFilename    string          `json:"readmeFilename"`
 	Contributors      []User          `json:"contributors"`
 	Users             map[string]bool `json:"users"`
+	// Modified is only part of abbreviated documents
+	Modified time.Time `json:"modified"`
 }
 
 type Version struct {
@@ -41,23 +63,29 @@ type Version struct {
 	Bugs        struct {
 		Url string `json:"url"`
 	} `json:"bugs"`
-	License         string                 `json:"license"`
-	Files           StringList             `json:"files"`
-	Main            string                 `json:"main"`
-	Engines         map[string]string      `json:"engines"`
-	Scripts         map[string]string      `json:"scripts"`
-	Dependencies    map[string]string      `json:"dependencies"`
-	Verb            map[string]interface{} `json:"verb"`
-	Keywords        StringList             `json:"keywords"`
-	DevDependencies map[string]string      `json:"devDependencies"`
-	GitHead         string                 `json:"gitHead"`
-	Id              string                 `json:"_id"`
-	Shasum          string                 `json:"_shasum"`
-	From            string                 `json:"_from"`
-	NpmVersion      string                 `json:"_npmVersion"`
-	NodeVersion     string                 `json:"_nodeVersion"`
-	NpmUser         User                   `json:"_npmUser"`
-	Dist            struct {
+	License              License                `json:"license"`
+	Files                StringList             `json:"files"`
+	Main                 string                 `json:"main"`
+	Engines              map[string]string      `json:"engines"`
+	Scripts              map[string]string      `json:"scripts"`
+	Dependencies         map[string]string      `json:"dependencies"`
+	Verb                 map[string]interface{} `json:"verb"`
+	Deprecated           string                 `json:"deprecated"`
+	Keywords             StringList             `json:"keywords"`
+	DevDependencies      map[string]string      `json:"devDependencies"`
+	PeerDependencies     map[string]string      `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]struct {
+		Optional bool `json:"optional"`
+	} `json:"peerDependenciesMeta"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	GitHead              string            `json:"gitHead"`
+	Id                   string            `json:"_id"`
+	Shasum               string            `json:"_shasum"`
+	From                 string            `json:"_from"`
+	NpmVersion           string            `json:"_npmVersion"`
+	NodeVersion          string            `json:"_nodeVersion"`
+	NpmUser              User              `json:"_npmUser"`
+	Dist                 struct {
 		Shasum     string `json:"shasum"`
 		Tarball    string `json:"tarball"`
 		Integrity  string `json:"integrity"`
@@ -76,6 +104,19 @@ type Version struct {
 
 type StringList []string
 
+// License is either a SPDX expression or a legacy {"type": "MIT"} object
+type License string
+
+// TimeMap contains the publish time of every version as well as the created, modified and unpublished times
+type TimeMap map[string]time.Time
+
+// Requirement is a single dependency of a version
+type Requirement struct {
+	Name  string
+	Range string
+	Kind  string
+}
+
 type Repository struct {
 	Type string `json:"type"`
 	Url  string `json:"url"`
@@ -104,13 +145,30 @@ func (r *RepositoryResponse) Description() string {
 }
 
 func (r *RepositoryResponse) License() string {
-	return r.RepositoryLicense
+	return string(r.RepositoryLicense)
 }
 
 func (r *RepositoryResponse) Maintainer() string {
+	if len(r.Maintainers) == 0 {
+		return ""
+	}
 	return r.Maintainers[0].Name
 }
 
+//
+// MaintainerNames
+// @Description: Get the names of all maintainers
+// @receiver r *RepositoryResponse
+// @return []string
+func (r *RepositoryResponse) MaintainerNames() (names []string) {
+	for _, m := range r.Maintainers {
+		if m.Name != "" {
+			names = append(names, m.Name)
+		}
+	}
+	return
+}
+
 func (r *RepositoryResponse) Url() string {
 	return r.Repository.Url
 }
@@ -126,14 +184,263 @@ func (r *RepositoryResponse) Versions() (versions []string) {
 	return
 }
 
-func (r *RepositoryResponse) Dependencies() map[string]string {
-	return r.RepositoryVersions[r.DistTags.Latest].Dependencies
+//
+// Resolve
+// @Description: Resolve a dist-tag or version range to the best matching published version. Like npm, the
+// latest dist-tag is preferred if it satisfies the range
+// @receiver r *RepositoryResponse
+// @param spec string
+// @return string
+// @return error
+func (r *RepositoryResponse) Resolve(spec string) (string, error) {
+	if v, ok := r.DistTags[spec]; ok {
+		return v, nil
+	}
+	rng, err := semver.ParseRange(spec)
+	if err != nil {
+		return "", err
+	}
+	if latest, err := semver.Parse(r.DistTags[TagLatest]); err == nil && rng.Satisfies(latest) {
+		return latest.Original(), nil
+	}
+	if v := rng.MaxSatisfying(r.Versions()); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("npm: no version of %s satisfies %s", r.Name(), spec)
+}
+
+//
+// IsAbbreviated
+// @Description: Check if the document is an abbreviated install document. Abbreviated documents don't contain
+// readme, license, maintainer, repository or time data
+// @receiver r *RepositoryResponse
+// @return bool
+func (r *RepositoryResponse) IsAbbreviated() bool {
+	return r.Time == nil
 }
 
 func (r *RepositoryResponse) Original() interface{} {
 	return r
 }
 
+//
+// Deprecated
+// @Description: Get the deprecation message of a given version. Falls back to latest if the version is unknown
+// @receiver r *RepositoryResponse
+// @param version string
+// @return string an empty string if the version isn't deprecated
+func (r *RepositoryResponse) Deprecated(version string) string {
+	if v, ok := r.RepositoryVersions[version]; ok {
+		return v.Deprecated
+	}
+	return r.RepositoryVersions[r.DistTags[TagLatest]].Deprecated
+}
+
+//
+// Unpublished
+// @Description: Get the time the whole package has been unpublished
+// @receiver r *RepositoryResponse
+// @return time.Time
+// @return bool false if the package hasn't been unpublished
+func (r *RepositoryResponse) Unpublished() (time.Time, bool) {
+	t, ok := r.Time[timeUnpublished]
+	return t, ok
+}
+
+//
+// VersionUnpublished
+// @Description: Check if a given version has been published once but has been removed since
+// @receiver r *RepositoryResponse
+// @param version string
+// @return bool
+func (r *RepositoryResponse) VersionUnpublished(version string) bool {
+	_, published := r.Time[version]
+	_, exists := r.RepositoryVersions[version]
+	return published && !exists
+}
+
+//
+// LastPublish
+// @Description: Get the time of the most recent version publish
+// @receiver r *RepositoryResponse
+// @return time.Time
+func (r *RepositoryResponse) LastPublish() (last time.Time) {
+	for k, t := range r.Time {
+		if k == timeCreated || k == timeModified || k == timeUnpublished {
+			continue
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return
+}
+
+//
+// Requirements
+// @Description: Get all dependencies of the given kinds sorted by name. If a dependency is listed several times,
+// optional wins over prod, prod over peer and peer over dev - matching how npm installs them. Optional peers are
+// only returned for KindPeerOptional
+// @receiver v *Version
+// @param kinds ...string
+// @return []*Requirement
+func (v *Version) Requirements(kinds ...string) []*Requirement {
+	requirements := map[string]*Requirement{}
+	for _, kind := range []string{KindDev, KindPeerOptional, KindPeer, KindProd, KindOptional} {
+		follow := false
+		for _, k := range kinds {
+			follow = follow || k == kind
+		}
+		if !follow {
+			continue
+		}
+		for name, rng := range v.dependencies(kind) {
+			requirements[name] = &Requirement{Name: name, Range: rng, Kind: kind}
+		}
+	}
+
+	result := make([]*Requirement, 0, len(requirements))
+	for _, r := range requirements {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (v *Version) dependencies(kind string) map[string]string {
+	switch kind {
+	case KindDev:
+		return v.DevDependencies
+	case KindPeer, KindPeerOptional:
+		peers := map[string]string{}
+		for name, rng := range v.PeerDependencies {
+			if v.PeerDependenciesMeta[name].Optional == (kind == KindPeerOptional) {
+				peers[name] = rng
+			}
+		}
+		return peers
+	case KindOptional:
+		return v.OptionalDependencies
+	}
+	return v.Dependencies
+}
+
+//
+// UnmarshalJSON
+// @Description: Parse the time map. Unpublished packages contain an "unpublished" object instead of a time
+// @receiver tm *TimeMap
+// @param data []byte
+// @return error
+func (tm *TimeMap) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	result := TimeMap{}
+	for k, v := range raw {
+		var t time.Time
+		if err := json.Unmarshal(v, &t); err == nil {
+			result[k] = t
+			continue
+		}
+		var unpublished struct {
+			Time time.Time `json:"time"`
+		}
+		if err := json.Unmarshal(v, &unpublished); err == nil && k == timeUnpublished {
+			result[k] = unpublished.Time
+		}
+	}
+	*tm = result
+	return nil
+}
+
+//
+// UnmarshalJSON
+// @Description: Parse a license string or legacy license object
+// @receiver l *License
+// @param data []byte
+// @return error
+func (l *License) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err == nil {
+		*l = License(v)
+		return nil
+	}
+	var o struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &o); err == nil {
+		*l = License(o.Type)
+	}
+	return nil
+}
+
+//
+// UnmarshalJSON
+// @Description: Parse a user object or the "Name <email> (url)" shorthand
+// @receiver u *User
+// @param data []byte
+// @return error
+func (u *User) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err == nil {
+		*u = parseUser(v)
+		return nil
+	}
+	type user User
+	var o user
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil
+	}
+	*u = User(o)
+	return nil
+}
+
+//
+// UnmarshalJSON
+// @Description: Parse a repository object or the "github:user/repo" shorthand
+// @receiver r *Repository
+// @param data []byte
+// @return error
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err == nil {
+		*r = Repository{Url: v}
+		return nil
+	}
+	type repository Repository
+	var o repository
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil
+	}
+	*r = Repository(o)
+	return nil
+}
+
+//
+// parseUser
+// @Description: Parse the "Name <email> (url)" person shorthand
+// @param s string
+// @return User
+func parseUser(s string) (u User) {
+	if i := strings.Index(s, "("); i >= 0 {
+		if j := strings.Index(s[i:], ")"); j > 0 {
+			u.Url = strings.TrimSpace(s[i+1 : i+j])
+		}
+		s = s[:i]
+	}
+	if i := strings.Index(s, "<"); i >= 0 {
+		if j := strings.Index(s[i:], ">"); j > 0 {
+			u.Email = strings.TrimSpace(s[i+1 : i+j])
+		}
+		s = s[:i]
+	}
+	u.Name = strings.TrimSpace(s)
+	return
+}
+
 func (sl *StringList) UnmarshalJSON(data []byte) error {
 	if bytes.Compare(data, []byte("{}")) != 0 {
 		var v []string
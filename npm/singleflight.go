@@ -0,0 +1,76 @@
+package npm
+
+import "sync"
+
+// flightGroup suppresses duplicate in-flight calls - concurrent callers of the same key share one result
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+//
+// Do
+// @Description: Execute fn once per key at a time. Duplicate callers wait for and receive the original result
+// @receiver g *flightGroup
+// @param key string
+// @param fn func() (interface{}, error)
+// @return interface{}
+// @return error
+func (g *flightGroup) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err
+	}
+	c := &flightCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.value, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return c.value, c.err
+}
+
+//
+// parallel
+// @Description: Call fn for every index in [0, n) using a bounded number of workers
+// @param n int
+// @param workers int
+// @param fn func(i int)
+func parallel(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
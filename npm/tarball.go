@@ -0,0 +1,232 @@
+package npm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/webklex/juck/semver"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// versionRegex contains all characters of a semver version
+var versionRegex = regexp.MustCompile(`^[0-9A-Za-z.+-]+$`)
+
+// integrityAlgorithms contains the supported subresource integrity algorithms - strongest first
+var integrityAlgorithms = []struct {
+	name string
+	hash func() hash.Hash
+}{
+	{"sha512", sha512.New},
+	{"sha384", sha512.New384},
+	{"sha256", sha256.New},
+	{"sha1", sha1.New},
+}
+
+//
+// TarballHashes
+// @Description: Get the sha256 hash of every file within the tarball of a given version. Tarballs are
+// downloaded once and kept in the tarball cache directory
+// @receiver npm *Npm
+// @param v *Version
+// @return map[string]string file path relative to the package root => hash
+// @return error
+func (npm *Npm) TarballHashes(v *Version) (map[string]string, error) {
+	data, err := npm.tarball(v)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	hashes := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		// Older tarballs contain regular files flagged as TypeRegA
+		if h.Typeflag != tar.TypeReg && h.Typeflag != tar.TypeRegA {
+			continue
+		}
+		hash := sha256.New()
+		if _, err := io.Copy(hash, tr); err != nil {
+			return nil, err
+		}
+		// Strip the top level folder - usually "package/"
+		name := h.Name
+		if i := strings.Index(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		hashes[name] = hex.EncodeToString(hash.Sum(nil))
+	}
+	return hashes, nil
+}
+
+//
+// tarball
+// @Description: Load a tarball from the cache or download it. Tarballs are verified against the integrity (or
+// shasum) of their version - downloads which don't match are discarded and cached tarballs which don't match are
+// downloaded again
+// @receiver npm *Npm
+// @param v *Version
+// @return []byte
+// @return error
+func (npm *Npm) tarball(v *Version) ([]byte, error) {
+	if v.Dist.Tarball == "" {
+		return nil, errors.New("npm: version has no tarball")
+	}
+	filename := ""
+	if npm.tarballCache != "" {
+		var err error
+		if filename, err = npm.tarballFilename(v); err != nil {
+			return nil, err
+		}
+		if data, err := ioutil.ReadFile(filename); err == nil {
+			if verifyTarball(v, data) == nil {
+				return data, nil
+			}
+			_ = os.Remove(filename)
+		}
+	}
+
+	u, err := url.Parse(npm.tarballUrl(v.Dist.Tarball))
+	if err != nil {
+		return nil, err
+	}
+	if npm.offline {
+		return nil, &OfflineError{Url: u.String()}
+	}
+	data, err := npm.request(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyTarball(v, data); err != nil {
+		return nil, err
+	}
+
+	if filename != "" {
+		_ = writeTarball(filename, data)
+	}
+	return data, nil
+}
+
+//
+// verifyTarball
+// @Description: Verify a tarball against the strongest supported hash of the version integrity. Versions without
+// a supported integrity fall back to the sha1 shasum. Versions without either are accepted
+// @param v *Version
+// @param data []byte
+// @return error
+func verifyTarball(v *Version, data []byte) error {
+	integrity := map[string]string{}
+	for _, entry := range strings.Fields(v.Dist.Integrity) {
+		parts := strings.SplitN(entry, "-", 2)
+		if len(parts) == 2 {
+			// Options (sha512-hash?opt) are ignored
+			integrity[parts[0]] = strings.SplitN(parts[1], "?", 2)[0]
+		}
+	}
+	for _, algorithm := range integrityAlgorithms {
+		expected, ok := integrity[algorithm.name]
+		if !ok {
+			continue
+		}
+		h := algorithm.hash()
+		h.Write(data)
+		if base64.StdEncoding.EncodeToString(h.Sum(nil)) != expected {
+			return &IntegrityError{Name: v.Name, Version: v.Version, Algorithm: algorithm.name}
+		}
+		return nil
+	}
+	if v.Dist.Shasum != "" {
+		sum := sha1.Sum(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), v.Dist.Shasum) {
+			return &IntegrityError{Name: v.Name, Version: v.Version, Algorithm: "sha1"}
+		}
+	}
+	return nil
+}
+
+//
+// writeTarball
+// @Description: Write a verified tarball into the cache. It's written to a temporary file first, so parallel runs
+// never read incomplete tarballs
+// @param filename string
+// @param data []byte
+// @return error
+func writeTarball(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), tmpPrefix)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+//
+// tarballFilename
+// @Description: Get the cache path of a tarball. Name and version are taken from the registry and therefore
+// validated - otherwise a malicious registry could write outside the cache directory
+// @receiver npm *Npm
+// @param v *Version
+// @return string
+// @return error
+func (npm *Npm) tarballFilename(v *Version) (string, error) {
+	if err := ValidateName(v.Name); err != nil {
+		return "", err
+	}
+	if _, err := semver.Parse(v.Version); err != nil || !versionRegex.MatchString(v.Version) {
+		return "", fmt.Errorf("npm: invalid version \"%s\" of %s", v.Version, v.Name)
+	}
+	filename := filepath.Join(npm.tarballCache, filepath.FromSlash(v.Name), v.Version+".tgz")
+	if rel, err := filepath.Rel(npm.tarballCache, filename); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("npm: tarball of %s@%s is outside of the cache", v.Name, v.Version)
+	}
+	return filename, nil
+}
+
+//
+// tarballUrl
+// @Description: Point tarball urls of the public registry to the configured registry (e.g. a local mirror)
+// @receiver npm *Npm
+// @param tarball string
+// @return string
+func (npm *Npm) tarballUrl(tarball string) string {
+	if registry := npm.config.Registry; registry != DefaultRegistry && strings.HasPrefix(tarball, DefaultRegistry) {
+		return registry + strings.TrimPrefix(tarball, DefaultRegistry)
+	}
+	return tarball
+}
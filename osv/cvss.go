@@ -0,0 +1,132 @@
+package osv
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	SeverityNone     = "NONE"
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// cvss3Weights contains the base metric weights of the CVSS v3.x specification. The privileges required weight
+// depends on the scope and is handled separately
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+//
+// cvssLevel
+// @Description: Get the qualitative severity of a CVSS score. The score is either a CVSS v3.x vector or a plain
+// base score
+// @param score string
+// @return string severityUnknown if the score can't be evaluated
+func cvssLevel(score string) string {
+	base, ok := 0.0, false
+	if strings.HasPrefix(score, "CVSS:3.") {
+		base, ok = cvss3BaseScore(score)
+	} else if f, err := strconv.ParseFloat(strings.TrimSpace(score), 64); err == nil && f >= 0 && f <= 10 {
+		base, ok = f, true
+	}
+	if !ok {
+		return severityUnknown
+	}
+	switch {
+	case base == 0:
+		return SeverityNone
+	case base < 4:
+		return SeverityLow
+	case base < 7:
+		return SeverityMedium
+	case base < 9:
+		return SeverityHigh
+	}
+	return SeverityCritical
+}
+
+//
+// cvss3BaseScore
+// @Description: Calculate the base score of a CVSS v3.x vector (CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H)
+// @param vector string
+// @return float64
+// @return bool false if the vector is incomplete or invalid
+func cvss3BaseScore(vector string) (float64, bool) {
+	metrics := map[string]string{}
+	for _, part := range strings.Split(vector, "/")[1:] {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return 0, false
+		}
+		metrics[kv[0]] = kv[1]
+	}
+
+	weights := map[string]float64{}
+	for metric, values := range cvss3Weights {
+		w, ok := values[metrics[metric]]
+		if !ok {
+			return 0, false
+		}
+		weights[metric] = w
+	}
+	changed := false
+	switch metrics["S"] {
+	case "U":
+	case "C":
+		changed = true
+	default:
+		return 0, false
+	}
+	switch metrics["PR"] {
+	case "N":
+		weights["PR"] = 0.85
+	case "L":
+		weights["PR"] = 0.62
+		if changed {
+			weights["PR"] = 0.68
+		}
+	case "H":
+		weights["PR"] = 0.27
+		if changed {
+			weights["PR"] = 0.5
+		}
+	default:
+		return 0, false
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * weights["PR"] * weights["UI"]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+//
+// cvssRoundUp
+// @Description: Round up to one decimal as defined by CVSS v3.1 (avoids floating point artifacts such as 4.000001)
+// @param f float64
+// @return float64
+func cvssRoundUp(f float64) float64 {
+	i := int64(math.Round(f * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
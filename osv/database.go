@@ -0,0 +1,146 @@
+package osv
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Database struct {
+	entries map[string][]*Entry
+	count   int
+}
+
+//
+// Load
+// @Description: Load an OSV database dump from a directory or zip archive
+// @param filename string
+// @return *Database
+// @return error
+func Load(filename string) (*Database, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &Database{
+		entries: map[string][]*Entry{},
+	}
+	if info.IsDir() {
+		err = db.loadDir(filename)
+	} else if strings.HasSuffix(strings.ToLower(filename), ".zip") {
+		err = db.loadZip(filename)
+	} else {
+		var data []byte
+		if data, err = ioutil.ReadFile(filename); err == nil {
+			err = db.add(filename, data)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+//
+// Count
+// @Description: Get the number of loaded npm entries
+// @receiver db *Database
+// @return int
+func (db *Database) Count() int {
+	return db.count
+}
+
+//
+// Match
+// @Description: Find all entries affecting a given package version. If the version is unknown, all
+// entries referring to the package are returned
+// @receiver db *Database
+// @param name string
+// @param version string
+// @return []*Entry
+func (db *Database) Match(name, version string) (result []*Entry) {
+	for _, e := range db.entries[name] {
+		if version == "" || e.Affects(name, version) {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return
+}
+
+func (db *Database) loadDir(dir string) error {
+	return filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasSuffix(filename, ".json") == false {
+			return nil
+		}
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		return db.add(filename, data)
+	})
+}
+
+func (db *Database) loadZip(filename string) error {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || strings.HasSuffix(f.Name, ".json") == false {
+			continue
+		}
+		fh, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(fh)
+		_ = fh.Close()
+		if err != nil {
+			return err
+		}
+		if err := db.add(f.Name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *Database) add(filename string, data []byte) error {
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return fmt.Errorf("osv: failed to parse \"%s\": %s", filename, err.Error())
+	}
+	if e.Withdrawn != nil {
+		return nil
+	}
+
+	added := false
+	for _, a := range e.Affected {
+		if a.Package.Ecosystem != EcosystemNpm {
+			continue
+		}
+		if known := db.entries[a.Package.Name]; len(known) > 0 && known[len(known)-1] == &e {
+			continue
+		}
+		db.entries[a.Package.Name] = append(db.entries[a.Package.Name], &e)
+		added = true
+	}
+	if added {
+		db.count++
+	}
+	return nil
+}
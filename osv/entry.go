@@ -0,0 +1,213 @@
+package osv
+
+import (
+	"github.com/webklex/juck/semver"
+	"sort"
+	"time"
+)
+
+const (
+	EcosystemNpm = "npm"
+
+	RangeSemver     = "SEMVER"
+	RangeEcosystem  = "ECOSYSTEM"
+	RangeGit        = "GIT"
+	introducedZero  = "0"
+	severityUnknown = "UNKNOWN"
+)
+
+type Entry struct {
+	ID         string     `json:"id"`
+	Modified   time.Time  `json:"modified"`
+	Published  time.Time  `json:"published"`
+	Withdrawn  *time.Time `json:"withdrawn"`
+	Aliases    []string   `json:"aliases"`
+	Summary    string     `json:"summary"`
+	Details    string     `json:"details"`
+	Severity   []Severity `json:"severity"`
+	Affected   []Affected `json:"affected"`
+	References []struct {
+		Type string `json:"type"`
+		Url  string `json:"url"`
+	} `json:"references"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type Affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+		Purl      string `json:"purl"`
+	} `json:"package"`
+	Ranges   []Range  `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+//
+// SeverityLevel
+// @Description: Get the most descriptive severity of the entry. CVSS scores are mapped to their qualitative
+// severity (NONE, LOW, MEDIUM, HIGH, CRITICAL)
+// @receiver e *Entry
+// @return string
+func (e *Entry) SeverityLevel() string {
+	if e.DatabaseSpecific.Severity != "" {
+		return e.DatabaseSpecific.Severity
+	}
+	for _, s := range e.Severity {
+		if level := cvssLevel(s.Score); level != severityUnknown {
+			return level
+		}
+	}
+	return severityUnknown
+}
+
+//
+// Urls
+// @Description: Get all reference urls
+// @receiver e *Entry
+// @return []string
+func (e *Entry) Urls() (urls []string) {
+	for _, r := range e.References {
+		urls = append(urls, r.Url)
+	}
+	return
+}
+
+//
+// Fixed
+// @Description: Get all versions fixing the given package
+// @receiver e *Entry
+// @param name string
+// @return []string
+func (e *Entry) Fixed(name string) (versions []string) {
+	for _, a := range e.affected(name) {
+		for _, r := range a.Ranges {
+			for _, ev := range r.Events {
+				if ev.Fixed != "" {
+					versions = append(versions, ev.Fixed)
+				}
+			}
+		}
+	}
+	return
+}
+
+//
+// Affects
+// @Description: Check if the given version of a package is affected by this entry
+// @receiver e *Entry
+// @param name string
+// @param version string
+// @return bool
+func (e *Entry) Affects(name, version string) bool {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return false
+	}
+	for _, a := range e.affected(name) {
+		for _, av := range a.Versions {
+			if w, err := semver.Parse(av); err == nil && w.Compare(v) == 0 {
+				return true
+			}
+		}
+		for _, r := range a.Ranges {
+			if r.Type != RangeSemver && r.Type != RangeEcosystem {
+				continue
+			}
+			if r.affects(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//
+// affected
+// @Description: Get all affected sections referring to the given npm package
+// @receiver e *Entry
+// @param name string
+// @return []Affected
+func (e *Entry) affected(name string) (result []Affected) {
+	for _, a := range e.Affected {
+		if a.Package.Ecosystem == EcosystemNpm && a.Package.Name == name {
+			result = append(result, a)
+		}
+	}
+	return
+}
+
+//
+// affects
+// @Description: Evaluate the range events in sorted order as described by the OSV specification
+// @receiver r Range
+// @param v *semver.Version
+// @return bool
+func (r Range) affects(v *semver.Version) bool {
+	events := make([]Event, len(r.Events))
+	copy(events, r.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return compareEventVersion(events[i].version(), events[j].version()) < 0
+	})
+
+	affected := false
+	for _, ev := range events {
+		ver := ev.version()
+		if ev.Introduced != "" {
+			if ver == introducedZero || compareEventVersion(v.String(), ver) >= 0 {
+				affected = true
+			}
+		} else if ev.Fixed != "" || ev.Limit != "" {
+			if compareEventVersion(v.String(), ver) >= 0 {
+				affected = false
+			}
+		} else if ev.LastAffected != "" {
+			if compareEventVersion(v.String(), ver) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func (ev Event) version() string {
+	switch {
+	case ev.Introduced != "":
+		return ev.Introduced
+	case ev.Fixed != "":
+		return ev.Fixed
+	case ev.LastAffected != "":
+		return ev.LastAffected
+	}
+	return ev.Limit
+}
+
+func compareEventVersion(a, b string) int {
+	if a == b {
+		return 0
+	} else if a == introducedZero {
+		return -1
+	} else if b == introducedZero {
+		return 1
+	}
+	return semver.Compare(a, b)
+}
@@ -0,0 +1,99 @@
+package osv
+
+import "testing"
+
+func TestSeverityLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    Entry
+		expected string
+	}{
+		{"database severity", entryWithSeverity("MODERATE", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), "MODERATE"},
+		{"critical vector", entryWithSeverity("", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), SeverityCritical},
+		{"high vector", entryWithSeverity("", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"), SeverityHigh},
+		{"medium vector", entryWithSeverity("", "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"), SeverityMedium},
+		{"low vector", entryWithSeverity("", "CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"), SeverityLow},
+		{"no impact", entryWithSeverity("", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N"), SeverityNone},
+		{"scope changed", entryWithSeverity("", "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H"), SeverityCritical},
+		{"numeric score", entryWithSeverity("", "7.5"), SeverityHigh},
+		{"incomplete vector", entryWithSeverity("", "CVSS:3.1/AV:N/AC:L"), severityUnknown},
+		{"cvss v4 vector", entryWithSeverity("", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"), severityUnknown},
+		{"no severity", Entry{}, severityUnknown},
+	}
+	for _, tt := range tests {
+		if level := tt.entry.SeverityLevel(); level != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, level)
+		}
+	}
+}
+
+func TestCvss3BaseScore(t *testing.T) {
+	tests := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H": 7.5,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N": 6.1,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H": 9.9,
+		"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N": 1.8,
+	}
+	for vector, expected := range tests {
+		if score, ok := cvss3BaseScore(vector); !ok || score != expected {
+			t.Errorf("%s: expected %.1f, got %.1f (%t)", vector, expected, score, ok)
+		}
+	}
+}
+
+func TestAffects(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected Affected
+		version  string
+		expected bool
+	}{
+		{"introduced zero", affectedRange(RangeSemver, Event{Introduced: "0"}, Event{Fixed: "1.2.3"}), "0.0.1", true},
+		{"before fixed", affectedRange(RangeSemver, Event{Introduced: "1.0.0"}, Event{Fixed: "1.2.3"}), "1.2.2", true},
+		{"fixed", affectedRange(RangeSemver, Event{Introduced: "1.0.0"}, Event{Fixed: "1.2.3"}), "1.2.3", false},
+		{"before introduced", affectedRange(RangeSemver, Event{Introduced: "1.0.0"}, Event{Fixed: "1.2.3"}), "0.9.0", false},
+		{"last affected", affectedRange(RangeSemver, Event{Introduced: "1.0.0"}, Event{LastAffected: "1.2.3"}), "1.2.3", true},
+		{"after last affected", affectedRange(RangeSemver, Event{Introduced: "1.0.0"}, Event{LastAffected: "1.2.3"}), "1.2.4", false},
+		{"limit", affectedRange(RangeSemver, Event{Introduced: "0"}, Event{Limit: "2.0.0"}), "2.0.0", false},
+		{"prerelease before fixed", affectedRange(RangeSemver, Event{Introduced: "0"}, Event{Fixed: "2.0.0"}), "2.0.0-rc.1", true},
+		{"unsorted events", affectedRange(RangeSemver, Event{Fixed: "1.2.3"}, Event{Introduced: "2.0.0"}, Event{Fixed: "2.1.0"}, Event{Introduced: "1.0.0"}), "2.0.5", true},
+		{"between ranges", affectedRange(RangeSemver, Event{Fixed: "1.2.3"}, Event{Introduced: "2.0.0"}, Event{Fixed: "2.1.0"}, Event{Introduced: "1.0.0"}), "1.5.0", false},
+		{"ecosystem range", affectedRange(RangeEcosystem, Event{Introduced: "0"}, Event{Fixed: "4.17.21"}), "4.17.20", true},
+		{"ecosystem fixed", affectedRange(RangeEcosystem, Event{Introduced: "0"}, Event{Fixed: "4.17.21"}), "4.17.21", false},
+		{"git range ignored", affectedRange(RangeGit, Event{Introduced: "0"}, Event{Fixed: "a1b2c3"}), "1.0.0", false},
+		{"affected version", Affected{Versions: []string{"1.0.0", "1.0.1"}}, "1.0.1", true},
+		{"unaffected version", Affected{Versions: []string{"1.0.0", "1.0.1"}}, "1.0.2", false},
+		{"invalid version", affectedRange(RangeSemver, Event{Introduced: "0"}), "latest", false},
+	}
+	for _, tt := range tests {
+		tt.affected.Package.Ecosystem = EcosystemNpm
+		tt.affected.Package.Name = "lodash"
+		e := Entry{Affected: []Affected{tt.affected}}
+		if affects := e.Affects("lodash", tt.version); affects != tt.expected {
+			t.Errorf("%s: expected %t for %s, got %t", tt.name, tt.expected, tt.version, affects)
+		}
+	}
+}
+
+func TestAffectsOtherEcosystem(t *testing.T) {
+	a := affectedRange(RangeEcosystem, Event{Introduced: "0"})
+	a.Package.Ecosystem, a.Package.Name = "PyPI", "lodash"
+	e := Entry{Affected: []Affected{a}}
+	if e.Affects("lodash", "1.0.0") {
+		t.Error("expected packages of other ecosystems to be ignored")
+	}
+}
+
+func entryWithSeverity(database, score string) Entry {
+	e := Entry{}
+	e.DatabaseSpecific.Severity = database
+	if score != "" {
+		e.Severity = []Severity{{Type: "CVSS_V3", Score: score}}
+	}
+	return e
+}
+
+func affectedRange(kind string, events ...Event) Affected {
+	return Affected{Ranges: []Range{{Type: kind, Events: events}}}
+}
@@ -0,0 +1,272 @@
+package report
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"sort"
+	"time"
+)
+
+const (
+	Filename     = "report.json"
+	SBOMFilename = "sbom.json"
+)
+
+type Report struct {
+	Generated    time.Time      `json:"generated"`
+	Packages     []*Package     `json:"packages"`
+	Dependencies []string       `json:"dependencies"`
+	Confusion    []*Candidate   `json:"confusion,omitempty"`
+	Risks        []*Risk        `json:"risks,omitempty"`
+	Secrets      []*Secret      `json:"secrets,omitempty"`
+	Endpoints    []*Endpoint    `json:"endpoints,omitempty"`
+	Environment  []*Environment `json:"environment,omitempty"`
+	Indicators   []*Indicator   `json:"indicators,omitempty"`
+	Comments     []*Comment     `json:"comments,omitempty"`
+	Builds       []*Build       `json:"builds,omitempty"`
+	Assets       []*Asset       `json:"assets,omitempty"`
+	Stylesheets  []*StyleImport `json:"stylesheets,omitempty"`
+	Findings     []*Finding     `json:"findings,omitempty"`
+}
+
+type Package struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	Classification string      `json:"classification,omitempty"`
+	Confidence     float64     `json:"confidence,omitempty"`
+	Evidence       []*Evidence `json:"evidence,omitempty"`
+	Advisories     []*Advisory `json:"advisories,omitempty"`
+	Metadata       *Metadata   `json:"metadata,omitempty"`
+	Files          []string    `json:"files,omitempty"`
+	FileCount      int         `json:"file_count"`
+}
+
+// Metadata contains the registry information of a package
+type Metadata struct {
+	License     string   `json:"license,omitempty"`
+	Homepage    string   `json:"homepage,omitempty"`
+	Repository  string   `json:"repository,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+}
+
+// Candidate is a package name prone to dependency confusion
+type Candidate struct {
+	Name           string   `json:"name"`
+	Scope          string   `json:"scope,omitempty"`
+	Classification string   `json:"classification"`
+	Sources        []string `json:"sources"`
+}
+
+// Risk is a maintenance issue of a discovered package such as a deprecation
+type Risk struct {
+	Package     string     `json:"package"`
+	Version     string     `json:"version,omitempty"`
+	Kind        string     `json:"kind"`
+	Message     string     `json:"message"`
+	LastPublish *time.Time `json:"last_publish,omitempty"`
+}
+
+// Asset is an embedded (data uri) asset decoded from a recovered file
+type Asset struct {
+	// File is the path of the decoded asset relative to the output directory
+	File string `json:"file"`
+	// Module is the recovered file the asset has been embedded in
+	Module    string `json:"module"`
+	MimeType  string `json:"mime_type"`
+	Size      int    `json:"size"`
+	Sha256    string `json:"sha256"`
+	SourceMap string `json:"source_map"`
+}
+
+// StyleImport is an edge of the stylesheet import graph (@use, @forward, @import, @require or @tailwind)
+type StyleImport struct {
+	From string `json:"from"`
+	// To is the resolved recovered file or the unresolved specifier
+	To        string `json:"to"`
+	Kind      string `json:"kind"`
+	Specifier string `json:"specifier"`
+	// Package is the npm package providing the imported stylesheet
+	Package string `json:"package,omitempty"`
+}
+
+// Finding is the common result of all analyzers
+type Finding struct {
+	Analyzer  string `json:"analyzer"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	SourceMap string `json:"source_map,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+}
+
+// Secret is a (redacted) secret found within a recovered file
+type Secret struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	SourceMap   string `json:"source_map"`
+	Match       string `json:"match"`
+}
+
+// Endpoint is an api endpoint, route or url referenced by recovered sources. The method of GraphQL endpoints is
+// the operation type and the url the operation name
+type Endpoint struct {
+	Kind   string   `json:"kind"`
+	Method string   `json:"method,omitempty"`
+	Url    string   `json:"url"`
+	Host   string   `json:"host,omitempty"`
+	Files  []string `json:"files"`
+}
+
+// Environment contains the environment variables and config files of a single target
+type Environment struct {
+	Target    string         `json:"target"`
+	Variables []*EnvVariable `json:"variables"`
+	Files     []string       `json:"files"`
+}
+
+type EnvVariable struct {
+	Name    string   `json:"name"`
+	Sources []string `json:"sources,omitempty"`
+	Values  []string `json:"values,omitempty"`
+	Files   []string `json:"files"`
+}
+
+// Indicator is an internal hostname, email, private ip address, cloud bucket or username
+type Indicator struct {
+	Kind  string   `json:"kind"`
+	Value string   `json:"value"`
+	Files []string `json:"files"`
+}
+
+// Comment is a developer comment containing at least one keyword
+type Comment struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Kind     string   `json:"kind"`
+	Text     string   `json:"text"`
+	Keywords []string `json:"keywords"`
+}
+
+// Build contains the bundler and framework that produced a target
+type Build struct {
+	Target              string   `json:"target"`
+	Bundler             string   `json:"bundler,omitempty"`
+	BundlerVersion      string   `json:"bundler_version,omitempty"`
+	BundlerConfidence   float64  `json:"bundler_confidence,omitempty"`
+	Framework           string   `json:"framework,omitempty"`
+	FrameworkVersion    string   `json:"framework_version,omitempty"`
+	FrameworkConfidence float64  `json:"framework_confidence,omitempty"`
+	Evidence            []string `json:"evidence,omitempty"`
+}
+
+type Evidence struct {
+	Version    string  `json:"version"`
+	Kind       string  `json:"kind"`
+	File       string  `json:"file"`
+	Confidence float64 `json:"confidence"`
+}
+
+type Advisory struct {
+	ID         string   `json:"id"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Summary    string   `json:"summary"`
+	Severity   string   `json:"severity"`
+	Fixed      []string `json:"fixed,omitempty"`
+	References []string `json:"references,omitempty"`
+	// Confirmed is false if the package version is unknown and the advisory may not apply
+	Confirmed bool `json:"confirmed"`
+}
+
+//
+// New
+// @Description: Create a new empty Report
+// @return *Report
+func New() *Report {
+	return &Report{
+		Generated:    time.Now(),
+		Packages:     make([]*Package, 0),
+		Dependencies: make([]string, 0),
+	}
+}
+
+//
+// Load
+// @Description: Load a previously written report from a given output directory
+// @param dir string
+// @return *Report
+// @return error
+func Load(dir string) (*Report, error) {
+	data, err := ioutil.ReadFile(path.Join(dir, Filename))
+	if err != nil {
+		return nil, err
+	}
+	r := New()
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//
+// Package
+// @Description: Get a package by name - it gets created if it doesn't exist
+// @receiver r *Report
+// @param name string
+// @return *Package
+func (r *Report) Package(name string) *Package {
+	for _, p := range r.Packages {
+		if p.Name == name {
+			return p
+		}
+	}
+	p := &Package{Name: name}
+	r.Packages = append(r.Packages, p)
+	return p
+}
+
+//
+// AddFindings
+// @Description: Add analyzer findings to the report
+// @receiver r *Report
+// @param findings ...*Finding
+func (r *Report) AddFindings(findings ...*Finding) {
+	r.Findings = append(r.Findings, findings...)
+}
+
+//
+// Save
+// @Description: Write the report and the matching SBOM into a given output directory
+// @receiver r *Report
+// @param dir string
+// @return error
+func (r *Report) Save(dir string) error {
+	sort.Slice(r.Packages, func(i, j int) bool {
+		return r.Packages[i].Name < r.Packages[j].Name
+	})
+	sort.Strings(r.Dependencies)
+
+	if err := WriteJson(path.Join(dir, Filename), r); err != nil {
+		return err
+	}
+	return WriteJson(path.Join(dir, SBOMFilename), NewSBOM(r))
+}
+
+//
+// WriteJson
+// @Description: Write a given value as indented json file
+// @param filename string
+// @param v interface{}
+// @return error
+func WriteJson(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0600)
+}
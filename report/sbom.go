@@ -0,0 +1,148 @@
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SBOM is a minimal CycloneDX 1.4 document
+type SBOM struct {
+	BomFormat       string           `json:"bomFormat"`
+	SpecVersion     string           `json:"specVersion"`
+	Version         int              `json:"version"`
+	Metadata        SBOMMetadata     `json:"metadata"`
+	Components      []*Component     `json:"components"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type SBOMMetadata struct {
+	Timestamp time.Time `json:"timestamp"`
+	Tools     []struct {
+		Vendor string `json:"vendor"`
+		Name   string `json:"name"`
+	} `json:"tools"`
+}
+
+type Component struct {
+	BomRef  string `json:"bom-ref"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Purl    string `json:"purl"`
+	// Licenses contains SPDX license expressions
+	Licenses []struct {
+		Expression string `json:"expression"`
+	} `json:"licenses,omitempty"`
+}
+
+type Vulnerability struct {
+	ID     string `json:"id"`
+	Source struct {
+		Name string `json:"name"`
+	} `json:"source"`
+	Ratings []struct {
+		Severity string `json:"severity"`
+	} `json:"ratings,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Recommendation string `json:"recommendation,omitempty"`
+	Advisories     []struct {
+		Url string `json:"url"`
+	} `json:"advisories,omitempty"`
+	Affects []struct {
+		Ref string `json:"ref"`
+	} `json:"affects"`
+}
+
+//
+// NewSBOM
+// @Description: Build a CycloneDX SBOM based on a given report
+// @param r *Report
+// @return *SBOM
+func NewSBOM(r *Report) *SBOM {
+	s := &SBOM{
+		BomFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Components:  make([]*Component, 0),
+	}
+	s.Metadata.Timestamp = r.Generated
+	s.Metadata.Tools = append(s.Metadata.Tools, struct {
+		Vendor string `json:"vendor"`
+		Name   string `json:"name"`
+	}{Vendor: "webklex", Name: "juck"})
+
+	for _, p := range r.Packages {
+		c := &Component{
+			Type:    "library",
+			Name:    p.Name,
+			Version: p.Version,
+			Purl:    Purl(p.Name, p.Version),
+		}
+		if i := strings.Index(p.Name, "/"); i > 0 && strings.HasPrefix(p.Name, "@") {
+			c.Group, c.Name = p.Name[:i], p.Name[i+1:]
+		}
+		c.BomRef = c.Purl
+		if p.Metadata != nil && p.Metadata.License != "" {
+			c.Licenses = append(c.Licenses, struct {
+				Expression string `json:"expression"`
+			}{Expression: p.Metadata.License})
+		}
+		s.Components = append(s.Components, c)
+
+		for _, a := range p.Advisories {
+			v := &Vulnerability{
+				ID:          a.ID,
+				Description: a.Summary,
+			}
+			v.Source.Name = "OSV"
+			if a.Severity != "" {
+				v.Ratings = append(v.Ratings, struct {
+					Severity string `json:"severity"`
+				}{Severity: cycloneDXSeverity(a.Severity)})
+			}
+			if len(a.Fixed) > 0 {
+				v.Recommendation = fmt.Sprintf("Upgrade %s to %s", p.Name, strings.Join(a.Fixed, " or "))
+			}
+			for _, u := range a.References {
+				v.Advisories = append(v.Advisories, struct {
+					Url string `json:"url"`
+				}{Url: u})
+			}
+			v.Affects = append(v.Affects, struct {
+				Ref string `json:"ref"`
+			}{Ref: c.BomRef})
+			s.Vulnerabilities = append(s.Vulnerabilities, v)
+		}
+	}
+	return s
+}
+
+//
+// Purl
+// @Description: Build the package url of a given npm package
+// @param name string
+// @param version string
+// @return string
+func Purl(name, version string) string {
+	purl := "pkg:npm/" + strings.ReplaceAll(url.PathEscape(name), "%2F", "/")
+	if strings.HasPrefix(name, "@") {
+		purl = strings.Replace(purl, "@", "%40", 1)
+	}
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+	return purl
+}
+
+func cycloneDXSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "high", "medium", "low", "info", "none":
+		return strings.ToLower(severity)
+	case "moderate":
+		return "medium"
+	}
+	return "unknown"
+}
@@ -0,0 +1,131 @@
+package secrets
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"math"
+	"regexp"
+)
+
+// Rule describes a single kind of secret
+type Rule struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	Regex       string `yaml:"regex"`
+	// SecretGroup is the regex group containing the secret (0 = whole match)
+	SecretGroup int `yaml:"secret_group"`
+	// Entropy is the minimal shannon entropy of the secret (0 = disabled)
+	Entropy float64 `yaml:"entropy"`
+	regex   *regexp.Regexp
+}
+
+// Allowlist suppresses findings in matching files or with matching secrets
+type Allowlist struct {
+	Paths     []string `yaml:"paths"`
+	Regexes   []string `yaml:"regexes"`
+	Stopwords []string `yaml:"stopwords"`
+	paths     []*regexp.Regexp
+	regexes   []*regexp.Regexp
+}
+
+// Config is the content of a custom rule file
+type Config struct {
+	// Extend keeps the built-in rules if set to true
+	Extend    bool      `yaml:"extend"`
+	Rules     []*Rule   `yaml:"rules"`
+	Allowlist Allowlist `yaml:"allowlist"`
+}
+
+// DefaultRules are the built-in rules
+var DefaultRules = []*Rule{
+	{ID: "aws-access-key-id", Description: "AWS access key id", Regex: `\b((?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[0-9A-Z]{16})\b`, SecretGroup: 1},
+	{ID: "aws-secret-access-key", Description: "AWS secret access key", Regex: `(?i)aws.{0,20}?(?:secret|key).{0,20}?['"]([0-9a-zA-Z/+]{40})['"]`, SecretGroup: 1, Entropy: 4},
+	{ID: "gcp-service-account", Description: "GCP service account private key", Regex: `"private_key_id"\s*:\s*"([0-9a-f]{40})"`, SecretGroup: 1},
+	{ID: "gcp-api-key", Description: "GCP api key", Regex: `\b(AIza[0-9A-Za-z\-_]{35})\b`, SecretGroup: 1},
+	{ID: "stripe-secret-key", Description: "Stripe secret or restricted key", Regex: `\b((?:sk|rk)_(?:live|test)_[0-9a-zA-Z]{24,99})\b`, SecretGroup: 1},
+	{ID: "slack-token", Description: "Slack token", Regex: `\b(xox[abposr]-[0-9A-Za-z-]{10,72})\b`, SecretGroup: 1},
+	{ID: "slack-webhook", Description: "Slack webhook url", Regex: `https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`},
+	{ID: "github-token", Description: "GitHub token", Regex: `\b((?:ghp|gho|ghu|ghs|ghr)_[0-9A-Za-z]{36}|github_pat_[0-9A-Za-z_]{82})\b`, SecretGroup: 1},
+	{ID: "jwt", Description: "JSON web token", Regex: `\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`, SecretGroup: 1},
+	{ID: "private-key", Description: "Private key", Regex: `-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`},
+	{ID: "generic-secret", Description: "High entropy secret assignment", Regex: `(?i)\b[\w-]*(?:api[_-]?key|secret|token|passw(?:or)?d|pwd|access[_-]?key|auth[_-]?key)[\w-]*["']?\s*[:=]\s*["']([^"'\s]{12,})["']`, SecretGroup: 1, Entropy: 3.5},
+}
+
+//
+// LoadConfig
+// @Description: Load custom rules and allowlists from a given yaml file
+// @param filename string
+// @return *Config
+// @return error
+func LoadConfig(filename string) (*Config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("secrets: failed to parse %s: %s", filename, err.Error())
+	}
+	return c, nil
+}
+
+//
+// compile
+// @Description: Compile the rule expression
+// @receiver r *Rule
+// @return error
+func (r *Rule) compile() (err error) {
+	if r.ID == "" {
+		return fmt.Errorf("secrets: rule without id")
+	}
+	if r.regex, err = regexp.Compile(r.Regex); err != nil {
+		return fmt.Errorf("secrets: invalid regex of rule %s: %s", r.ID, err.Error())
+	}
+	if r.SecretGroup < 0 || r.SecretGroup > r.regex.NumSubexp() {
+		return fmt.Errorf("secrets: rule %s has no group %d", r.ID, r.SecretGroup)
+	}
+	return nil
+}
+
+//
+// compile
+// @Description: Compile all allowlist expressions
+// @receiver a *Allowlist
+// @return error
+func (a *Allowlist) compile() error {
+	a.paths, a.regexes = nil, nil
+	for _, p := range a.Paths {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("secrets: invalid allowlist path %s: %s", p, err.Error())
+		}
+		a.paths = append(a.paths, r)
+	}
+	for _, p := range a.Regexes {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("secrets: invalid allowlist regex %s: %s", p, err.Error())
+		}
+		a.regexes = append(a.regexes, r)
+	}
+	return nil
+}
+
+//
+// entropy
+// @Description: Calculate the shannon entropy of a given string
+// @param s string
+// @return float64
+func entropy(s string) (e float64) {
+	counts := map[rune]int{}
+	for _, c := range s {
+		counts[c]++
+	}
+	l := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / l
+		e -= p * math.Log2(p)
+	}
+	return
+}
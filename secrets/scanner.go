@@ -0,0 +1,137 @@
+package secrets
+
+import (
+	"strings"
+)
+
+// Finding is a single secret found within a file
+type Finding struct {
+	Rule        string
+	Description string
+	File        string
+	Line        int
+	// Match is the redacted secret
+	Match string
+}
+
+type Scanner struct {
+	rules     []*Rule
+	allowlist Allowlist
+}
+
+//
+// NewScanner
+// @Description: Create a new Scanner using the built-in rules
+// @return *Scanner
+func NewScanner() *Scanner {
+	s := &Scanner{}
+	for _, r := range DefaultRules {
+		rule := *r
+		if err := rule.compile(); err != nil {
+			panic(err)
+		}
+		s.rules = append(s.rules, &rule)
+	}
+	return s
+}
+
+//
+// Configure
+// @Description: Apply the custom rules and allowlist of a given configuration. The built-in rules are replaced
+// unless the configuration extends them. Custom rules replace built-in rules with the same id
+// @receiver s *Scanner
+// @param c *Config
+// @return error
+func (s *Scanner) Configure(c *Config) error {
+	if err := c.Allowlist.compile(); err != nil {
+		return err
+	}
+	if !c.Extend && len(c.Rules) > 0 {
+		s.rules = nil
+	}
+	for _, r := range c.Rules {
+		if err := r.compile(); err != nil {
+			return err
+		}
+		replaced := false
+		for i, existing := range s.rules {
+			if existing.ID == r.ID {
+				s.rules[i], replaced = r, true
+			}
+		}
+		if !replaced {
+			s.rules = append(s.rules, r)
+		}
+	}
+	s.allowlist.Paths = append(s.allowlist.Paths, c.Allowlist.Paths...)
+	s.allowlist.Regexes = append(s.allowlist.Regexes, c.Allowlist.Regexes...)
+	s.allowlist.Stopwords = append(s.allowlist.Stopwords, c.Allowlist.Stopwords...)
+	return s.allowlist.compile()
+}
+
+//
+// Scan
+// @Description: Scan the content of a given file line by line
+// @receiver s *Scanner
+// @param file string
+// @param content string
+// @param lineOffset int number of lines preceding the content within the file
+// @return []*Finding
+func (s *Scanner) Scan(file, content string, lineOffset int) (findings []*Finding) {
+	for _, r := range s.allowlist.paths {
+		if r.MatchString(file) {
+			return
+		}
+	}
+	for i, line := range strings.Split(content, "\n") {
+		for _, rule := range s.rules {
+			for _, m := range rule.regex.FindAllStringSubmatch(line, -1) {
+				secret := m[rule.SecretGroup]
+				if secret == "" || (rule.Entropy > 0 && entropy(secret) < rule.Entropy) || s.allowed(secret) {
+					continue
+				}
+				findings = append(findings, &Finding{
+					Rule:        rule.ID,
+					Description: rule.Description,
+					File:        file,
+					Line:        lineOffset + i + 1,
+					Match:       Redact(secret),
+				})
+			}
+		}
+	}
+	return
+}
+
+//
+// allowed
+// @Description: Check if a given secret is allowlisted
+// @receiver s *Scanner
+// @param secret string
+// @return bool
+func (s *Scanner) allowed(secret string) bool {
+	for _, r := range s.allowlist.regexes {
+		if r.MatchString(secret) {
+			return true
+		}
+	}
+	lower := strings.ToLower(secret)
+	for _, w := range s.allowlist.Stopwords {
+		if strings.Contains(lower, strings.ToLower(w)) {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// Redact
+// @Description: Mask all but the first four and the last two characters of a given secret
+// @param secret string
+// @return string
+func Redact(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-6) + secret[len(secret)-2:]
+}
@@ -0,0 +1,337 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	opEqual        = "="
+	opLess         = "<"
+	opLessEqual    = "<="
+	opGreater      = ">"
+	opGreaterEqual = ">="
+)
+
+var (
+	operatorSpaceRegex = regexp.MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`)
+	hyphenRegex        = regexp.MustCompile(`^\s*(\S+)\s+-\s+(\S+)\s*$`)
+	partialRegex       = regexp.MustCompile(`^v?(\*|[xX]|\d+)(?:\.(\*|[xX]|\d+))?(?:\.(\*|[xX]|\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+)
+
+// Range is a npm style version range - a list of comparator sets joined by "||"
+type Range struct {
+	sets     [][]*comparator
+	original string
+}
+
+type comparator struct {
+	operator string
+	version  *Version
+}
+
+// partial is a version which may be missing its minor or patch part (e.g.: 1.x or 1.2)
+type partial struct {
+	major, minor, patch uint64
+	// parts is the number of given numeric parts (0 = any)
+	parts      int
+	prerelease []string
+}
+
+//
+// ParseRange
+// @Description: Parse a npm version range (e.g.: ^1.2.3, ~1.2, 1.x, 1.2.3 - 2.3.4, >=1.0.0 <2.0.0 || 3.x)
+// @param str string
+// @return *Range
+// @return error
+func ParseRange(str string) (*Range, error) {
+	r := &Range{original: str}
+	for _, set := range strings.Split(str, "||") {
+		comparators, err := parseComparatorSet(set)
+		if err != nil {
+			return nil, fmt.Errorf("semver: invalid range \"%s\": %s", str, err.Error())
+		}
+		r.sets = append(r.sets, comparators)
+	}
+	return r, nil
+}
+
+//
+// Satisfies
+// @Description: Check if a given version satisfies the range. Prerelease versions only satisfy a comparator set
+// if the set contains a prerelease of the same major.minor.patch tuple
+// @receiver r *Range
+// @param v *Version
+// @return bool
+func (r *Range) Satisfies(v *Version) bool {
+	for _, set := range r.sets {
+		if testSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// String
+// @Description: Get the range as it was parsed
+// @receiver r *Range
+// @return string
+func (r *Range) String() string {
+	return r.original
+}
+
+//
+// MaxSatisfying
+// @Description: Get the highest version of a list satisfying the range
+// @receiver r *Range
+// @param versions []string
+// @return string an empty string if no version satisfies the range
+func (r *Range) MaxSatisfying(versions []string) string {
+	var best *Version
+	for _, str := range versions {
+		v, err := Parse(str)
+		if err != nil || r.Satisfies(v) == false {
+			continue
+		}
+		if best == nil || v.Compare(best) > 0 {
+			best = v
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Original()
+}
+
+func testSet(set []*comparator, v *Version) bool {
+	for _, c := range set {
+		if c.test(v) == false {
+			return false
+		}
+	}
+	if v.IsPrerelease() == false {
+		return true
+	}
+	for _, c := range set {
+		if c.version.IsPrerelease() && c.version.Major == v.Major && c.version.Minor == v.Minor && c.version.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparator) test(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.operator {
+	case opLess:
+		return cmp < 0
+	case opLessEqual:
+		return cmp <= 0
+	case opGreater:
+		return cmp > 0
+	case opGreaterEqual:
+		return cmp >= 0
+	}
+	return cmp == 0
+}
+
+func parseComparatorSet(set string) ([]*comparator, error) {
+	if m := hyphenRegex.FindStringSubmatch(set); m != nil {
+		from, err := parsePartial(m[1])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePartial(m[2])
+		if err != nil {
+			return nil, err
+		}
+		result := expand(opGreaterEqual, from)
+		switch to.parts {
+		case 0:
+		case 3:
+			result = append(result, newComparator(opLessEqual, to.major, to.minor, to.patch, to.prerelease))
+		default:
+			result = append(result, expand(opLessEqual, to)...)
+		}
+		return result, nil
+	}
+
+	set = strings.TrimSpace(operatorSpaceRegex.ReplaceAllString(set, "$1"))
+	result := make([]*comparator, 0)
+	for _, token := range strings.Fields(set) {
+		comparators, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, comparators...)
+	}
+	if len(result) == 0 {
+		// An empty set matches everything
+		result = append(result, newComparator(opGreaterEqual, 0, 0, 0, nil))
+	}
+	return result, nil
+}
+
+func parseComparator(token string) ([]*comparator, error) {
+	operator := ""
+	for _, op := range []string{"~>", ">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(token, op) {
+			operator = op
+			token = token[len(op):]
+			break
+		}
+	}
+	p, err := parsePartial(token)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator {
+	case "^":
+		return expandCaret(p), nil
+	case "~", "~>":
+		return expandTilde(p), nil
+	case "", opEqual:
+		return expand(opEqual, p), nil
+	}
+	return expand(operator, p), nil
+}
+
+func parsePartial(str string) (*partial, error) {
+	m := partialRegex.FindStringSubmatch(strings.TrimSpace(str))
+	if m == nil {
+		return nil, fmt.Errorf("invalid version \"%s\"", str)
+	}
+	p := &partial{}
+	numbers := []*uint64{&p.major, &p.minor, &p.patch}
+	for i, part := range m[1:4] {
+		if part == "" || part == "*" || part == "x" || part == "X" {
+			break
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		*numbers[i] = n
+		p.parts++
+	}
+	if m[4] != "" && p.parts == 3 {
+		p.prerelease = strings.Split(m[4], ".")
+	}
+	return p, nil
+}
+
+//
+// expand
+// @Description: Turn a primitive comparison with a partial version into explicit comparators
+// @param operator string
+// @param p *partial
+// @return []*comparator
+func expand(operator string, p *partial) []*comparator {
+	if p.parts == 3 {
+		return []*comparator{newComparator(operator, p.major, p.minor, p.patch, p.prerelease)}
+	}
+
+	// lower and upper bound of the partial version (e.g. 1.2 => >=1.2.0 <1.3.0-0)
+	lower := newComparator(opGreaterEqual, p.major, p.minor, 0, nil)
+	upper := newComparator(opLess, p.major+1, 0, 0, []string{"0"})
+	if p.parts == 2 {
+		upper = newComparator(opLess, p.major, p.minor+1, 0, []string{"0"})
+	}
+
+	switch operator {
+	case opGreaterEqual:
+		if p.parts == 0 {
+			return []*comparator{newComparator(opGreaterEqual, 0, 0, 0, nil)}
+		}
+		return []*comparator{lower}
+	case opGreater:
+		if p.parts == 0 {
+			// Nothing can be greater than any version
+			return []*comparator{newComparator(opLess, 0, 0, 0, []string{"0"})}
+		}
+		upper.operator = opGreaterEqual
+		upper.version.Prerelease = nil
+		return []*comparator{upper}
+	case opLess:
+		if p.parts == 0 {
+			return []*comparator{newComparator(opLess, 0, 0, 0, []string{"0"})}
+		}
+		lower.operator = opLess
+		lower.version.Prerelease = []string{"0"}
+		return []*comparator{lower}
+	case opLessEqual:
+		if p.parts == 0 {
+			return []*comparator{newComparator(opGreaterEqual, 0, 0, 0, nil)}
+		}
+		return []*comparator{upper}
+	}
+
+	if p.parts == 0 {
+		return []*comparator{newComparator(opGreaterEqual, 0, 0, 0, nil)}
+	}
+	return []*comparator{lower, upper}
+}
+
+//
+// expandTilde
+// @Description: ~1.2.3 => >=1.2.3 <1.3.0-0, ~1.2 => >=1.2.0 <1.3.0-0, ~1 => >=1.0.0 <2.0.0-0
+// @param p *partial
+// @return []*comparator
+func expandTilde(p *partial) []*comparator {
+	if p.parts < 3 {
+		return expand(opEqual, p)
+	}
+	return []*comparator{
+		newComparator(opGreaterEqual, p.major, p.minor, p.patch, p.prerelease),
+		newComparator(opLess, p.major, p.minor+1, 0, []string{"0"}),
+	}
+}
+
+//
+// expandCaret
+// @Description: Allow changes that do not modify the left-most non-zero part
+// (^1.2.3 => >=1.2.3 <2.0.0-0, ^0.2.3 => >=0.2.3 <0.3.0-0, ^0.0.3 => >=0.0.3 <0.0.4-0)
+// @param p *partial
+// @return []*comparator
+func expandCaret(p *partial) []*comparator {
+	switch {
+	case p.parts == 0:
+		return expand(opEqual, p)
+	case p.parts == 1 || (p.parts == 2 && p.major > 0):
+		return []*comparator{
+			newComparator(opGreaterEqual, p.major, p.minor, 0, nil),
+			newComparator(opLess, p.major+1, 0, 0, []string{"0"}),
+		}
+	case p.parts == 2:
+		return []*comparator{
+			newComparator(opGreaterEqual, 0, p.minor, 0, nil),
+			newComparator(opLess, 0, p.minor+1, 0, []string{"0"}),
+		}
+	}
+
+	lower := newComparator(opGreaterEqual, p.major, p.minor, p.patch, p.prerelease)
+	if p.major > 0 {
+		return []*comparator{lower, newComparator(opLess, p.major+1, 0, 0, []string{"0"})}
+	} else if p.minor > 0 {
+		return []*comparator{lower, newComparator(opLess, 0, p.minor+1, 0, []string{"0"})}
+	}
+	return []*comparator{lower, newComparator(opLess, 0, 0, p.patch+1, []string{"0"})}
+}
+
+func newComparator(operator string, major, minor, patch uint64, prerelease []string) *comparator {
+	v := &Version{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		Prerelease: prerelease,
+	}
+	v.original = v.String()
+	return &comparator{
+		operator: operator,
+		version:  v,
+	}
+}
@@ -0,0 +1,183 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      string
+	original   string
+}
+
+//
+// Parse
+// @Description: Parse a given semantic version string (e.g.: 1.2.3-beta.1+build.5)
+// @param str string
+// @return *Version
+// @return error
+func Parse(str string) (*Version, error) {
+	original := str
+	str = strings.TrimSpace(str)
+	str = strings.TrimLeft(str, "=v")
+	if str == "" {
+		return nil, fmt.Errorf("semver: empty version")
+	}
+
+	v := &Version{original: original}
+	if i := strings.Index(str, "+"); i >= 0 {
+		v.Build = str[i+1:]
+		str = str[:i]
+	}
+	if i := strings.Index(str, "-"); i >= 0 {
+		if str[i+1:] == "" {
+			return nil, fmt.Errorf("semver: invalid prerelease in \"%s\"", original)
+		}
+		v.Prerelease = strings.Split(str[i+1:], ".")
+		str = str[:i]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("semver: invalid version \"%s\"", original)
+	}
+	numbers := make([]uint64, 3)
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("semver: invalid version \"%s\"", original)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+
+	return v, nil
+}
+
+//
+// MustParse
+// @Description: Parse a given semantic version string and panic if it is invalid
+// @param str string
+// @return *Version
+func MustParse(str string) *Version {
+	v, err := Parse(str)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+//
+// Compare
+// @Description: Compare two versions according to the semver precedence rules
+// @receiver v *Version
+// @param o *Version
+// @return int -1 if v < o, 0 if v == o and 1 if v > o
+func (v *Version) Compare(o *Version) int {
+	if c := compareNumber(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareNumber(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareNumber(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A version without prerelease has a higher precedence
+	if len(v.Prerelease) == 0 && len(o.Prerelease) == 0 {
+		return 0
+	} else if len(v.Prerelease) == 0 {
+		return 1
+	} else if len(o.Prerelease) == 0 {
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := comparePrerelease(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareNumber(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+//
+// IsPrerelease
+// @Description: Check if the version is a prerelease
+// @receiver v *Version
+// @return bool
+func (v *Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+//
+// String
+// @Description: Get the normalized version string
+// @receiver v *Version
+// @return string
+func (v *Version) String() string {
+	str := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		str += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		str += "+" + v.Build
+	}
+	return str
+}
+
+//
+// Original
+// @Description: Get the version string as it was parsed
+// @receiver v *Version
+// @return string
+func (v *Version) Original() string {
+	return v.original
+}
+
+//
+// Compare
+// @Description: Compare two version strings. Invalid versions are considered lower than valid ones
+// @param a string
+// @param b string
+// @return int
+func Compare(a, b string) int {
+	va, erra := Parse(a)
+	vb, errb := Parse(b)
+	if erra != nil && errb != nil {
+		return strings.Compare(a, b)
+	} else if erra != nil {
+		return -1
+	} else if errb != nil {
+		return 1
+	}
+	return va.Compare(vb)
+}
+
+func compareNumber(a, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func comparePrerelease(a, b string) int {
+	na, erra := strconv.ParseUint(a, 10, 64)
+	nb, errb := strconv.ParseUint(b, 10, 64)
+	if erra == nil && errb == nil {
+		return compareNumber(na, nb)
+	} else if erra == nil {
+		// Numeric identifiers always have lower precedence
+		return -1
+	} else if errb == nil {
+		return 1
+	}
+	return strings.Compare(a, b)
+}
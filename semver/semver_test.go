@@ -0,0 +1,106 @@
+package semver
+
+import "testing"
+
+func TestRangeSatisfies(t *testing.T) {
+	tests := []struct {
+		rng     string
+		version string
+		want    bool
+	}{
+		// caret
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^1.x", "1.5.0", true},
+		{"^1.x", "2.0.0", false},
+		// tilde
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~1.2", "1.3.0", false},
+		{"~1", "1.9.9", true},
+		{"~1", "2.0.0", false},
+		// x-ranges
+		{"*", "3.2.1", true},
+		{"", "0.0.1", true},
+		{"1.x", "1.0.0", true},
+		{"1.x", "2.0.0", false},
+		{"1.2.x", "1.2.7", true},
+		{"1.2.x", "1.3.0", false},
+		{"1", "1.4.2", true},
+		{"1.2", "1.2.5", true},
+		{"1.2", "1.3.0", false},
+		// hyphen
+		{"1.2.3 - 2.3.4", "1.2.3", true},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		{"1.2 - 2.3", "2.3.9", true},
+		{"1.2 - 2.3", "2.4.0", false},
+		{"1.2.3 - 2", "2.9.9", true},
+		{"1.2.3 - 2", "3.0.0", false},
+		// comparators and ||
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{"<1.0.0 || >=3.0.0", "0.9.0", true},
+		{"<1.0.0 || >=3.0.0", "2.0.0", false},
+		{"<1.0.0 || >=3.0.0", "3.1.0", true},
+		{"1.x || 3.x", "3.2.0", true},
+		{"= 1.2.3", "1.2.3", true},
+		{">= 1.2.3", "1.2.4", true},
+		// prerelease
+		{"^1.2.3-beta.2", "1.2.3-beta.3", true},
+		{"^1.2.3-beta.2", "1.2.3-beta.1", false},
+		{"^1.2.3-beta.2", "1.2.4-beta.1", false},
+		{"^1.2.3-beta.2", "1.3.0", true},
+		{"^1.2.3", "1.3.0-beta.1", false},
+		{">=1.0.0", "2.0.0-rc.1", false},
+		{"*", "1.0.0-alpha", false},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		if err != nil {
+			t.Errorf("ParseRange(%q): %s", tt.rng, err)
+			continue
+		}
+		if got := r.Satisfies(MustParse(tt.version)); got != tt.want {
+			t.Errorf("%q satisfies %q: expected %v, got %v", tt.version, tt.rng, tt.want, got)
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for _, rng := range []string{"^a.b.c", ">=1.2.3 <", "1.2.3.4", "~1.2.3-"} {
+		if _, err := ParseRange(rng); err == nil {
+			t.Errorf("ParseRange(%q): expected an error", rng)
+		}
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.10.1", "2.0.0-beta.1", "2.0.0", "3.0.0"}
+	tests := []struct {
+		rng  string
+		want string
+	}{
+		{"^1.0.0", "1.10.1"},
+		{"~1.2.0", "1.2.0"},
+		{"<2.0.0", "1.10.1"},
+		{">=2.0.0-beta.1 <3.0.0", "2.0.0"},
+		{"^4.0.0", ""},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.MaxSatisfying(versions); got != tt.want {
+			t.Errorf("MaxSatisfying(%q): expected %q, got %q", tt.rng, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "strings"
+
+// DotQuote quotes a string as Graphviz DOT identifier. Only backslashes and double quotes are escaped - Go escape
+// sequences (%q) aren't valid DOT
+func DotQuote(str string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(str) + "\""
+}